@@ -0,0 +1,132 @@
+package affinity
+
+import (
+	"errors"
+	"math/bits"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"go.vallahaye.net/cpuset"
+)
+
+const (
+	// initialMaskBitSize is the size of the first mask passed to the
+	// kernel, matching the cpu_set_t type of glibc.
+	initialMaskBitSize = 1024
+
+	// maxMaskBitSize bounds the growth of the mask when the kernel
+	// reports it too small.
+	maxMaskBitSize = 1 << 22
+
+	wordBitSize = bits.UintSize
+)
+
+// Get returns the CPU affinity of the process pid, that is the one of its
+// main thread. If pid is 0, the calling process is used.
+func Get(pid int) (cpuset.CPUSet, error) {
+	if pid == 0 {
+		pid = os.Getpid()
+	}
+
+	return GetThread(pid)
+}
+
+// Set changes the CPU affinity of all threads of the process pid to s. If
+// pid is 0, the calling process is used.
+//
+// Threads created while Set runs may be missed, as the threads of the
+// process are listed from /proc/pid/task.
+func Set(pid int, s cpuset.CPUSet) error {
+	if pid == 0 {
+		pid = os.Getpid()
+	}
+
+	dir, err := os.Open("/proc/" + strconv.Itoa(pid) + "/task")
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		tid, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+
+		// Threads exiting since the listing no longer need an affinity.
+		if err := SetThread(tid, s); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetThread returns the CPU affinity of the thread tid. If tid is 0, the
+// calling thread is used.
+func GetThread(tid int) (cpuset.CPUSet, error) {
+	// The kernel fails with EINVAL if the mask is smaller than the number
+	// of possible CPUs, so grow the mask until it fits.
+	for n := initialMaskBitSize; ; n *= 2 {
+		mask := make([]uint, n/wordBitSize)
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, uintptr(tid), uintptr(len(mask)*wordBitSize/8), uintptr(unsafe.Pointer(&mask[0])))
+		switch {
+		case errno == syscall.EINVAL && n < maxMaskBitSize:
+			continue
+		case errno != 0:
+			return cpuset.CPUSet{}, os.NewSyscallError("sched_getaffinity", errno)
+		}
+
+		return fromMask(mask), nil
+	}
+}
+
+// SetThread changes the CPU affinity of the thread tid to s. If tid is 0, the
+// calling thread is used.
+func SetThread(tid int, s cpuset.CPUSet) error {
+	mask := toMask(s)
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, uintptr(tid), uintptr(len(mask)*wordBitSize/8), uintptr(unsafe.Pointer(&mask[0])))
+	if errno != 0 {
+		return os.NewSyscallError("sched_setaffinity", errno)
+	}
+
+	return nil
+}
+
+// fromMask decodes the kernel mask into a CPUSet. The kernel stores CPU n as
+// the bit n%wordBitSize of mask[n/wordBitSize].
+func fromMask(mask []uint) cpuset.CPUSet {
+	var s cpuset.CPUSet
+	for i, word := range mask {
+		for word != 0 {
+			bit := bits.TrailingZeros(word)
+			s.Add(uint(i*wordBitSize + bit))
+			word &= word - 1
+		}
+	}
+
+	return s
+}
+
+// toMask encodes s into a kernel mask. The mask is never empty, so that
+// its address can be passed to the kernel.
+func toMask(s cpuset.CPUSet) []uint {
+	size := uint(1)
+	if cpu, ok := s.Max(); ok {
+		size = cpu/wordBitSize + 1
+	}
+
+	mask := make([]uint, size)
+	for cpu := range s.All() {
+		mask[cpu/wordBitSize] |= 1 << (cpu % wordBitSize)
+	}
+
+	return mask
+}
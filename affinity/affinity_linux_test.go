@@ -0,0 +1,119 @@
+package affinity
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"go.vallahaye.net/cpuset"
+)
+
+func TestGet(t *testing.T) {
+	s1, err := Get(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s2, err := Get(os.Getpid())
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case s1.Len() == 0:
+		t.Error("unexpected empty affinity")
+	case !s1.Equal(s2):
+		t.Errorf("unexpected affinity: got %v, want %v", s2, s1)
+	}
+}
+
+func TestGetNoSuchProcess(t *testing.T) {
+	if _, err := Get(1 << 30); !errors.Is(err, syscall.ESRCH) {
+		t.Errorf("unexpected error: got %v, want %v", err, syscall.ESRCH)
+	}
+}
+
+func TestSetThread(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	orig, err := GetThread(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cpu, _ := orig.Min()
+	if err := SetThread(0, cpuset.Of(cpu)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer SetThread(0, orig)
+
+	switch got, err := GetThread(0); {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !got.Equal(cpuset.Of(cpu)):
+		t.Errorf("unexpected affinity: got %v, want %v", got, cpuset.Of(cpu))
+	}
+}
+
+func TestSetThreadEmpty(t *testing.T) {
+	if err := SetThread(0, cpuset.CPUSet{}); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("unexpected error: got %v, want %v", err, syscall.EINVAL)
+	}
+}
+
+func TestSet(t *testing.T) {
+	orig, err := Get(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cpu, _ := orig.Min()
+	if err := Set(0, cpuset.Of(cpu)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer Set(0, orig)
+
+	// Every thread of the process is affected, including the calling one
+	// whichever it is.
+	switch got, err := GetThread(0); {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !got.Equal(cpuset.Of(cpu)):
+		t.Errorf("unexpected affinity: got %v, want %v", got, cpuset.Of(cpu))
+	}
+}
+
+func TestMask(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    cpuset.CPUSet
+		size int
+	}{
+		{
+			name: "empty",
+			s:    cpuset.CPUSet{},
+			size: 1,
+		},
+		{
+			name: "CPUs 0 and 63",
+			s:    cpuset.Of(0, 63),
+			size: 64 / wordBitSize,
+		},
+		{
+			name: "CPUs beyond 1024",
+			s:    cpuset.Of(1, 1024, 5000),
+			size: 5000/wordBitSize + 1,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			mask := toMask(params.s)
+			switch got := fromMask(mask); {
+			case len(mask) != params.size:
+				t.Errorf("unexpected mask size: got %d, want %d", len(mask), params.size)
+			case !got.Equal(params.s):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.s)
+			}
+		})
+	}
+}
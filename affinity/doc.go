@@ -0,0 +1,6 @@
+// Package affinity reads and changes the CPU affinity of processes and
+// threads as [cpuset.CPUSet] values, using the sched_getaffinity(2) and
+// sched_setaffinity(2) system calls of Linux.
+//
+// It is only available on Linux.
+package affinity
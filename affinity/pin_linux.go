@@ -0,0 +1,54 @@
+package affinity
+
+import (
+	"runtime"
+	"sync"
+
+	"go.vallahaye.net/cpuset"
+)
+
+// PinCurrentThread locks the calling goroutine to its OS thread, as
+// [runtime.LockOSThread] does, and changes the CPU affinity of the thread to
+// s. The restore function returned resets the prior affinity of the thread,
+// then unlocks the goroutine from it. Calling restore more than once has no
+// effect.
+//
+// If the prior affinity cannot be reset, restore leaves the goroutine
+// locked, so that the thread is terminated rather than reused by other
+// goroutines once the calling goroutine exits.
+func PinCurrentThread(s cpuset.CPUSet) (restore func(), _ error) {
+	runtime.LockOSThread()
+
+	prev, err := GetThread(0)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, err
+	}
+
+	if err := SetThread(0, s); err != nil {
+		runtime.UnlockOSThread()
+		return nil, err
+	}
+
+	return sync.OnceFunc(func() {
+		if err := SetThread(0, prev); err != nil {
+			return
+		}
+
+		runtime.UnlockOSThread()
+	}), nil
+}
+
+// Do calls fn with the calling goroutine pinned to a thread whose CPU
+// affinity is s (see [PinCurrentThread]). It returns an error, without
+// calling fn, if the affinity cannot be changed.
+func Do(s cpuset.CPUSet, fn func()) error {
+	restore, err := PinCurrentThread(s)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	fn()
+	return nil
+}
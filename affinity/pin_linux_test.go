@@ -0,0 +1,89 @@
+package affinity
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"go.vallahaye.net/cpuset"
+)
+
+func TestPinCurrentThread(t *testing.T) {
+	// Keep the goroutine on the same thread after restore, so that the
+	// affinity restored can be checked.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	orig, err := GetThread(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cpu, _ := orig.Min()
+	restore, err := PinCurrentThread(cpuset.Of(cpu))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	switch got, err := GetThread(0); {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !got.Equal(cpuset.Of(cpu)):
+		t.Errorf("unexpected affinity: got %v, want %v", got, cpuset.Of(cpu))
+	}
+
+	restore()
+	restore()
+
+	switch got, err := GetThread(0); {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !got.Equal(orig):
+		t.Errorf("unexpected affinity: got %v, want %v", got, orig)
+	}
+}
+
+func TestPinCurrentThreadEmpty(t *testing.T) {
+	if _, err := PinCurrentThread(cpuset.CPUSet{}); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("unexpected error: got %v, want %v", err, syscall.EINVAL)
+	}
+}
+
+func TestDo(t *testing.T) {
+	orig, err := GetThread(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cpu, _ := orig.Min()
+	called := false
+	err = Do(cpuset.Of(cpu), func() {
+		called = true
+
+		switch got, err := GetThread(0); {
+		case err != nil:
+			t.Errorf("unexpected error: %v", err)
+		case !got.Equal(cpuset.Of(cpu)):
+			t.Errorf("unexpected affinity: got %v, want %v", got, cpuset.Of(cpu))
+		}
+	})
+
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !called:
+		t.Error("expected fn to be called")
+	}
+}
+
+func TestDoError(t *testing.T) {
+	called := false
+	if err := Do(cpuset.CPUSet{}, func() { called = true }); err == nil {
+		t.Error("expected error")
+	}
+
+	if called {
+		t.Error("unexpected call of fn")
+	}
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"go.vallahaye.net/cpuset"
+	"go.vallahaye.net/cpuset/sysfs"
 )
 
 const (
@@ -14,12 +17,21 @@ const (
 	defaultFormat = listFormat
 )
 
-const usageHeader = `Usage: cpuset [flags] command s1 s2
+const (
+	onlineUniverse   = "online"
+	possibleUniverse = "possible"
+	defaultUniverse  = onlineUniverse
+)
+
+const usageHeader = `Usage: cpuset [flags] command s1 [s2]
 
 Flags:`
 
 const usageFooter = `
 Commands:
+  complement
+    	compute the complement of the first cpuset relative to the second one,
+    	or to the CPUs of the system if omitted (see -universe)
   difference
     	compute the difference of the two cpusets
   intersection
@@ -30,6 +42,7 @@ Commands:
 Examples:
   cpuset difference 0-32 8-16
   cpuset -format mask difference 00000001,ffffffff 0000ff00
+  cpuset -universe possible complement 0-1
 
 See also:
   man cpuset(7) for more information about cpusets`
@@ -46,14 +59,38 @@ func fail(text string) {
 	os.Exit(2)
 }
 
+// formatInvalidOperand describes why the named operand is invalid, pointing
+// at the offending element of the operand when known.
+func formatInvalidOperand(name string, err error) string {
+	text := name + " provided but invalid: " + err.Error()
+
+	var perr *cpuset.ParseError
+	if errors.As(err, &perr) {
+		text += "\n  " + perr.Input + "\n  " + strings.Repeat(" ", perr.Offset) + "^"
+	}
+
+	return text
+}
+
+// readUniverse returns the CPUs of the system in the named universe.
+func readUniverse(name string) (cpuset.CPUSet, error) {
+	if name == possibleUniverse {
+		return sysfs.Possible()
+	}
+
+	return sysfs.Online()
+}
+
 func main() {
 	var (
 		format       string
+		universe     string
 		printVersion bool
 	)
 
 	flag.Usage = usage
 	flag.StringVar(&format, "format", defaultFormat, "use the specified format for parsing the two cpusets and outputing the result")
+	flag.StringVar(&universe, "universe", defaultUniverse, "use the specified CPUs of the system (online or possible) when the second cpuset of the complement command is omitted")
 	flag.BoolVar(&printVersion, "version", false, "print the version and exit")
 	flag.Parse()
 
@@ -62,28 +99,33 @@ func main() {
 		os.Exit(0)
 	}
 
-	var (
-		parseFn  func(string) (cpuset.CPUSet, error)
-		stringFn func(*cpuset.CPUSet) string
-	)
+	var operandFormat cpuset.Format
 
 	switch format {
 	case listFormat:
-		parseFn, stringFn = cpuset.ParseList, (*cpuset.CPUSet).ListString
+		operandFormat = cpuset.ListFormat
 	case maskFormat:
-		parseFn, stringFn = cpuset.ParseMask, (*cpuset.CPUSet).MaskString
+		operandFormat = cpuset.MaskFormat
 	default:
 		fail("flag provided but invalid: -format")
 	}
 
+	switch universe {
+	case onlineUniverse, possibleUniverse:
+	default:
+		fail("flag provided but invalid: -universe")
+	}
+
 	args := flag.Args()
-	if len(args) != 3 {
+	if len(args) == 0 {
 		fail("invalid number of arguments")
 	}
 
 	var commandFn func(cpuset.CPUSet, cpuset.CPUSet) cpuset.CPUSet
 
 	switch args[0] {
+	case "complement":
+		commandFn = cpuset.Complement
 	case "difference":
 		commandFn = cpuset.Difference
 	case "intersection":
@@ -94,16 +136,28 @@ func main() {
 		fail("command provided but not defined: " + args[0])
 	}
 
-	s1, err := parseFn(args[1])
-	if err != nil {
-		fail("s1 provided but invalid: " + err.Error())
+	if len(args) != 3 && (len(args) != 2 || args[0] != "complement") {
+		fail("invalid number of arguments")
+	}
+
+	var s1, s2 cpuset.CPUSet
+	if err := cpuset.NewFlag(&s1, operandFormat).Set(args[1]); err != nil {
+		fail(formatInvalidOperand("s1", err))
 	}
 
-	s2, err := parseFn(args[2])
-	if err != nil {
-		fail("s2 provided but invalid: " + err.Error())
+	if len(args) == 3 {
+		if err := cpuset.NewFlag(&s2, operandFormat).Set(args[2]); err != nil {
+			fail(formatInvalidOperand("s2", err))
+		}
+	} else {
+		var err error
+		s2, err = readUniverse(universe)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "cpuset: reading the CPUs of the system: "+err.Error())
+			os.Exit(1)
+		}
 	}
 
 	s := commandFn(s1, s2)
-	fmt.Println(stringFn(&s))
+	fmt.Println(cpuset.NewFlag(&s, operandFormat))
 }
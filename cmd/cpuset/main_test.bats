@@ -28,12 +28,24 @@ function cpuset() {
   [[ "${lines[-1]}" = 'exit status 2' ]]
 }
 
+@test "flag provided but invalid: -universe" {
+  run -1 cpuset -universe invalid complement 0-32
+  [[ "${lines[0]}" = 'flag provided but invalid: -universe' ]]
+  [[ "${lines[-1]}" = 'exit status 2' ]]
+}
+
 @test "invalid number of arguments" {
   run -1 cpuset
   [[ "${lines[0]}" = 'invalid number of arguments' ]]
   [[ "${lines[-1]}" = 'exit status 2' ]]
 }
 
+@test "invalid number of arguments (difference)" {
+  run -1 cpuset difference 0-32
+  [[ "${lines[0]}" = 'invalid number of arguments' ]]
+  [[ "${lines[-1]}" = 'exit status 2' ]]
+}
+
 @test "command provided but not defined: undefined" {
   run -1 cpuset undefined 0-32 8-16
   [[ "${lines[0]}" = 'command provided but not defined: undefined' ]]
@@ -46,12 +58,40 @@ function cpuset() {
   [[ "${lines[-1]}" = 'exit status 2' ]]
 }
 
+@test "s1 provided but invalid (caret under the offending element)" {
+  run -1 cpuset difference 0-32,a 8-16
+  [[ "${lines[0]}" = 's1 provided but invalid:'* ]]
+  [[ "${lines[1]}" = *'0-32,a' ]]
+  [[ "${lines[2]}" = *'     ^' ]]
+  [[ "${lines[-1]}" = 'exit status 2' ]]
+}
+
 @test "s2 provided but invalid" {
   run -1 cpuset difference 0-32 s2
   [[ "${lines[0]}" = 's2 provided but invalid:'* ]]
   [[ "${lines[-1]}" = 'exit status 2' ]]
 }
 
+@test "compute the complement of the first cpuset (default format)" {
+  run -0 cpuset complement 8-16 0-32
+  [[ "$output" = '0-7,17-32' ]]
+}
+
+@test "compute the complement of the first cpuset (-format mask)" {
+  run -0 cpuset -format mask complement 0000ff00 00000001,ffffffff
+  [[ "$output" = '00000001,ffff00ff' ]]
+}
+
+@test "compute the complement of the first cpuset (-universe online)" {
+  run -0 cpuset -universe online complement 0
+  [[ "$output" != '0'* ]]
+}
+
+@test "compute the complement of the first cpuset (-universe possible)" {
+  run -0 cpuset -universe possible complement 0
+  [[ "$output" != '0'* ]]
+}
+
 @test "compute the difference of the two cpusets (default format)" {
   run -0 cpuset difference 0-32 8-16
   [[ "$output" = '0-7,17-32' ]]
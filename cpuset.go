@@ -1,24 +1,41 @@
 package cpuset
 
 import (
-	"fmt"
-	"maps"
+	"iter"
+	"math/bits"
+	"slices"
 )
 
+const blockBitSize = 64
+
 // A CPUSet defines a list of CPUs to restrict processes to.
 // The zero value of a CPUSet is ready to use.
+//
+// A CPUSet is backed by a bitset of 64-CPU blocks, up to the block of its
+// highest CPU. Copies of a CPUSet share this bitset as long as they span the
+// same blocks: adding or removing a CPU through one copy is visible through
+// the others, unless the change moves the highest CPU to another block. In
+// that case, as with [CPUSet.Clear], the changed copy gets its own bitset
+// and the others are left unchanged. Use [CPUSet.Clone] to obtain an
+// independent set.
 type CPUSet struct {
-	m map[uint]struct{}
+	// blocks stores CPU n as the bit n%64 of blocks[n/64]. Its last block is
+	// never zero, and it is reallocated rather than resliced whenever its
+	// length changes, so that all the copies sharing it have the same length.
+	blocks []uint64
 }
 
 // Of returns a new [CPUSet] containing the CPUs listed.
 func Of(cpus ...uint) CPUSet {
-	s := CPUSet{
-		m: make(map[uint]struct{}),
+	var s CPUSet
+	if len(cpus) == 0 {
+		return s
 	}
 
+	s.grow(slices.Max(cpus))
 	for _, cpu := range cpus {
-		s.m[cpu] = struct{}{}
+		i, bit := blockPos(cpu)
+		s.blocks[i] |= bit
 	}
 
 	return s
@@ -27,50 +44,144 @@ func Of(cpus ...uint) CPUSet {
 // Add adds a CPU to s.
 // It reports whether the CPU was not present before.
 func (s *CPUSet) Add(cpu uint) bool {
-	if s.m == nil {
-		s.m = make(map[uint]struct{})
+	s.grow(cpu)
+
+	i, bit := blockPos(cpu)
+	if s.blocks[i]&bit != 0 {
+		return false
 	}
 
-	n := len(s.m)
-	s.m[cpu] = struct{}{}
-	return len(s.m) > n
+	s.blocks[i] |= bit
+	return true
+}
+
+// AddRange adds the CPUs from lowerBound to upperBound inclusive to s.
+// It does nothing if upperBound is lower than lowerBound.
+func (s *CPUSet) AddRange(lowerBound, upperBound uint) {
+	if upperBound < lowerBound {
+		return
+	}
+
+	s.grow(upperBound)
+
+	i, j := int(lowerBound/blockBitSize), int(upperBound/blockBitSize)
+	lowerMask := ^uint64(0) << (lowerBound % blockBitSize)
+	upperMask := ^uint64(0) >> (blockBitSize - 1 - upperBound%blockBitSize)
+	if i == j {
+		s.blocks[i] |= lowerMask & upperMask
+		return
+	}
+
+	s.blocks[i] |= lowerMask
+	for k := i + 1; k < j; k++ {
+		s.blocks[k] = ^uint64(0)
+	}
+
+	s.blocks[j] |= upperMask
+}
+
+// AddSeq adds the CPUs yielded by seq to s.
+func (s *CPUSet) AddSeq(seq iter.Seq[uint]) {
+	for cpu := range seq {
+		s.Add(cpu)
+	}
 }
 
 // Delete removes a CPU from s.
 // It reports whether the CPU was present.
 func (s *CPUSet) Delete(cpu uint) bool {
-	n := len(s.m)
-	delete(s.m, cpu)
-	return len(s.m) < n
+	if !s.Contains(cpu) {
+		return false
+	}
+
+	i, bit := blockPos(cpu)
+	if i == len(s.blocks)-1 && s.blocks[i] == bit {
+		s.shrink(i)
+	} else {
+		s.blocks[i] &^= bit
+	}
+
+	return true
+}
+
+// DeleteSeq removes the CPUs yielded by seq from s.
+func (s *CPUSet) DeleteSeq(seq iter.Seq[uint]) {
+	for cpu := range seq {
+		s.Delete(cpu)
+	}
 }
 
 // Contains reports whether a CPU is present in s.
 func (s *CPUSet) Contains(cpu uint) bool {
-	_, ok := s.m[cpu]
-	return ok
+	i, bit := blockPos(cpu)
+	return i < len(s.blocks) && s.blocks[i]&bit != 0
 }
 
 // UnsortedList returns a slice of all the CPUs in s, in an unpredictable
 // order.
 func (s *CPUSet) UnsortedList() []uint {
-	cpus := make([]uint, len(s.m))
-	i := 0
-	for cpu := range s.m {
-		cpus[i] = cpu
-		i++
+	return slices.AppendSeq(make([]uint, 0, s.Len()), s.All())
+}
+
+// All returns an iterator over the CPUs in s, in ascending order.
+func (s *CPUSet) All() iter.Seq[uint] {
+	return func(yield func(uint) bool) {
+		for i, block := range s.blocks {
+			for ; block != 0; block &= block - 1 {
+				if !yield(uint(i)*blockBitSize + uint(bits.TrailingZeros64(block))) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the CPUs in s, in descending order.
+func (s *CPUSet) Backward() iter.Seq[uint] {
+	return func(yield func(uint) bool) {
+		for i := len(s.blocks) - 1; i >= 0; i-- {
+			for block := s.blocks[i]; block != 0; {
+				pos := uint(blockBitSize - 1 - bits.LeadingZeros64(block))
+				if !yield(uint(i)*blockBitSize + pos) {
+					return
+				}
+
+				block &^= uint64(1) << pos
+			}
+		}
 	}
+}
 
-	return cpus
+// Ranges returns an iterator over the ranges of contiguous CPUs in s, in
+// ascending order. Each range is yielded as its lower and upper bounds,
+// inclusive.
+func (s *CPUSet) Ranges() iter.Seq2[uint, uint] {
+	return func(yield func(uint, uint) bool) {
+		lowerBound, ok := s.nextSet(0)
+		for ok {
+			upperBound := s.nextClear(lowerBound) - 1
+			if !yield(lowerBound, upperBound) {
+				return
+			}
+
+			lowerBound, ok = s.nextSet(upperBound + 1)
+		}
+	}
 }
 
 // Equal reports whether s and s2 contain exactly the same CPUs.
 func (s *CPUSet) Equal(s2 CPUSet) bool {
-	if len(s.m) != len(s2.m) {
+	return slices.Equal(s.blocks, s2.blocks)
+}
+
+// IsSubset reports whether all the CPUs of s are in s2.
+func (s *CPUSet) IsSubset(s2 CPUSet) bool {
+	if len(s.blocks) > len(s2.blocks) {
 		return false
 	}
 
-	for cpu := range s2.m {
-		if _, ok := s.m[cpu]; !ok {
+	for i, block := range s.blocks {
+		if block&^s2.blocks[i] != 0 {
 			return false
 		}
 	}
@@ -78,73 +189,372 @@ func (s *CPUSet) Equal(s2 CPUSet) bool {
 	return true
 }
 
+// IsSuperset reports whether all the CPUs of s2 are in s.
+func (s *CPUSet) IsSuperset(s2 CPUSet) bool {
+	return s2.IsSubset(*s)
+}
+
+// IsDisjoint reports whether s and s2 have no CPU in common.
+func (s *CPUSet) IsDisjoint(s2 CPUSet) bool {
+	for i := range min(len(s.blocks), len(s2.blocks)) {
+		if s.blocks[i]&s2.blocks[i] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Overlaps reports whether s and s2 have at least one CPU in common.
+func (s *CPUSet) Overlaps(s2 CPUSet) bool {
+	return !s.IsDisjoint(s2)
+}
+
 // Clear removes all CPUs from s, leaving it empty.
 func (s *CPUSet) Clear() {
-	clear(s.m)
+	s.blocks = nil
 }
 
 // Clone returns a copy of s.
 func (s *CPUSet) Clone() CPUSet {
 	return CPUSet{
-		m: maps.Clone(s.m),
+		blocks: slices.Clone(s.blocks),
 	}
 }
 
 // Len returns the number of CPUs in s.
 func (s *CPUSet) Len() int {
-	return len(s.m)
+	n := 0
+	for _, block := range s.blocks {
+		n += bits.OnesCount64(block)
+	}
+
+	return n
+}
+
+// DifferenceWith removes the CPUs of s2 from s.
+func (s *CPUSet) DifferenceWith(s2 CPUSet) {
+	n := len(s.blocks)
+	for n > 0 && n <= len(s2.blocks) && s.blocks[n-1]&^s2.blocks[n-1] == 0 {
+		n--
+	}
+
+	if n < len(s.blocks) {
+		s.shrink(n)
+	}
+
+	for i := range min(len(s.blocks), len(s2.blocks)) {
+		s.blocks[i] &^= s2.blocks[i]
+	}
+}
+
+// IntersectWith removes the CPUs of s that are not in s2.
+func (s *CPUSet) IntersectWith(s2 CPUSet) {
+	n := min(len(s.blocks), len(s2.blocks))
+	for n > 0 && s.blocks[n-1]&s2.blocks[n-1] == 0 {
+		n--
+	}
+
+	if n < len(s.blocks) {
+		s.shrink(n)
+	}
+
+	for i := range s.blocks {
+		s.blocks[i] &= s2.blocks[i]
+	}
+}
+
+// UnionWith adds the CPUs of s2 to s.
+func (s *CPUSet) UnionWith(s2 CPUSet) {
+	if len(s2.blocks) > len(s.blocks) {
+		s.grow(s2.max())
+	}
+
+	for i, block := range s2.blocks {
+		s.blocks[i] |= block
+	}
+}
+
+// Min returns the lowest CPU of s.
+// It reports whether s is not empty.
+func (s *CPUSet) Min() (uint, bool) {
+	return s.nextSet(0)
+}
+
+// Max returns the highest CPU of s.
+// It reports whether s is not empty.
+func (s *CPUSet) Max() (uint, bool) {
+	if len(s.blocks) == 0 {
+		return 0, false
+	}
+
+	return s.max(), true
+}
+
+// Nth returns the CPU of s at index i, CPUs being sorted in ascending order.
+// It reports whether i is in the range [0, s.Len()).
+func (s *CPUSet) Nth(i int) (uint, bool) {
+	if i < 0 {
+		return 0, false
+	}
+
+	for j, block := range s.blocks {
+		if n := bits.OnesCount64(block); i >= n {
+			i -= n
+			continue
+		}
+
+		for ; i > 0; i-- {
+			block &= block - 1
+		}
+
+		return uint(j)*blockBitSize + uint(bits.TrailingZeros64(block)), true
+	}
+
+	return 0, false
+}
+
+// Rank returns the index of a CPU in s, CPUs being sorted in ascending
+// order. It reports whether the CPU is present.
+func (s *CPUSet) Rank(cpu uint) (int, bool) {
+	if !s.Contains(cpu) {
+		return 0, false
+	}
+
+	i, bit := blockPos(cpu)
+	n := bits.OnesCount64(s.blocks[i] & (bit - 1))
+	for _, block := range s.blocks[:i] {
+		n += bits.OnesCount64(block)
+	}
+
+	return n, true
+}
+
+// NextAfter returns the lowest CPU of s greater than cpu.
+// It reports whether such a CPU exists.
+func (s *CPUSet) NextAfter(cpu uint) (uint, bool) {
+	if cpu == ^uint(0) {
+		return 0, false
+	}
+
+	return s.nextSet(cpu + 1)
+}
+
+// PrevBefore returns the highest CPU of s lower than cpu.
+// It reports whether such a CPU exists.
+func (s *CPUSet) PrevBefore(cpu uint) (uint, bool) {
+	if cpu == 0 {
+		return 0, false
+	}
+
+	return s.prevSet(cpu - 1)
 }
 
 // String is an alias for [CPUSet.ListString].
-func (s *CPUSet) String() string {
+func (s CPUSet) String() string {
 	return s.ListString()
 }
 
-// Difference returns a new [CPUSet] containing the CPUs of s1 that are not
-// in s2.
-func Difference(s1, s2 CPUSet) CPUSet {
-	var s CPUSet
-	for cpu := range s1.m {
-		if _, ok := s2.m[cpu]; !ok {
-			s.Add(cpu)
+// grow extends the bitset of s so that it can hold cpu. The extended bitset
+// is always a new one, as the copies of s may share the spare capacity of
+// the current one.
+func (s *CPUSet) grow(cpu uint) {
+	if n := int(cpu/blockBitSize) + 1; n > len(s.blocks) {
+		s.blocks = append(s.blocks[:len(s.blocks):len(s.blocks)], make([]uint64, n-len(s.blocks))...)
+	}
+}
+
+// shrink replaces the bitset of s with a new one holding its first n
+// blocks, without their trailing zero blocks.
+func (s *CPUSet) shrink(n int) {
+	for n > 0 && s.blocks[n-1] == 0 {
+		n--
+	}
+
+	s.blocks = slices.Clone(s.blocks[:n])
+}
+
+// trim removes the trailing zero blocks of s, which must not share its
+// bitset.
+func (s *CPUSet) trim() {
+	n := len(s.blocks)
+	for n > 0 && s.blocks[n-1] == 0 {
+		n--
+	}
+
+	s.blocks = s.blocks[:n]
+}
+
+// addStride adds, from lowerBound to upperBound inclusive, the first used CPUs
+// of every group of CPUs of size group to s.
+func (s *CPUSet) addStride(lowerBound, upperBound, used, group uint) {
+	if used == 0 {
+		return
+	}
+
+	for start := lowerBound; ; start += group {
+		s.AddRange(start, start+min(used-1, upperBound-start))
+		if upperBound-start < group {
+			return
+		}
+	}
+}
+
+// nextSet returns the lowest CPU of s greater than or equal to cpu.
+// It reports whether such a CPU exists.
+func (s *CPUSet) nextSet(cpu uint) (uint, bool) {
+	i := int(cpu / blockBitSize)
+	if i >= len(s.blocks) {
+		return 0, false
+	}
+
+	block := s.blocks[i] &^ (uint64(1)<<(cpu%blockBitSize) - 1)
+	for block == 0 {
+		if i++; i == len(s.blocks) {
+			return 0, false
+		}
+
+		block = s.blocks[i]
+	}
+
+	return uint(i)*blockBitSize + uint(bits.TrailingZeros64(block)), true
+}
+
+// prevSet returns the highest CPU of s lower than or equal to cpu.
+// It reports whether such a CPU exists.
+func (s *CPUSet) prevSet(cpu uint) (uint, bool) {
+	if len(s.blocks) == 0 {
+		return 0, false
+	}
+
+	i := int(cpu / blockBitSize)
+	block := s.blocks[min(i, len(s.blocks)-1)]
+	if i < len(s.blocks) {
+		block &= ^uint64(0) >> (blockBitSize - 1 - cpu%blockBitSize)
+	} else {
+		i = len(s.blocks) - 1
+	}
+
+	for block == 0 {
+		if i--; i < 0 {
+			return 0, false
+		}
+
+		block = s.blocks[i]
+	}
+
+	return uint(i)*blockBitSize + uint(blockBitSize-1-bits.LeadingZeros64(block)), true
+}
+
+// nextClear returns the lowest CPU not in s greater than or equal to cpu.
+func (s *CPUSet) nextClear(cpu uint) uint {
+	i := int(cpu / blockBitSize)
+	if i >= len(s.blocks) {
+		return cpu
+	}
+
+	block := ^s.blocks[i] &^ (uint64(1)<<(cpu%blockBitSize) - 1)
+	for block == 0 {
+		if i++; i == len(s.blocks) {
+			return uint(i) * blockBitSize
 		}
+
+		block = ^s.blocks[i]
 	}
 
+	return uint(i)*blockBitSize + uint(bits.TrailingZeros64(block))
+}
+
+// max returns the highest CPU of s, which must not be empty.
+func (s *CPUSet) max() uint {
+	i := len(s.blocks) - 1
+	return uint(i)*blockBitSize + uint(blockBitSize-1-bits.LeadingZeros64(s.blocks[i]))
+}
+
+// Complement returns a new [CPUSet] containing the CPUs of universe that are
+// not in s.
+func Complement(s, universe CPUSet) CPUSet {
+	return Difference(universe, s)
+}
+
+// Difference returns a new [CPUSet] containing the CPUs of s1 that are not
+// in s2.
+func Difference(s1, s2 CPUSet) CPUSet {
+	s := s1.Clone()
+	s.DifferenceWith(s2)
 	return s
 }
 
 // Intersection returns a new [CPUSet] containing the CPUs of s1 that are
 // in s2.
 func Intersection(s1, s2 CPUSet) CPUSet {
-	var s CPUSet
-	m1, m2 := s1.m, s2.m
-	if len(m1) > len(m2) {
-		m2, m1 = m1, m2
+	return IntersectionOf(s1, s2)
+}
+
+// IntersectionOf returns a new [CPUSet] containing the CPUs present in all
+// the sets. It returns an empty set if no set is given.
+func IntersectionOf(sets ...CPUSet) CPUSet {
+	if len(sets) == 0 {
+		return CPUSet{}
 	}
 
-	for cpu := range m1 {
-		if _, ok := m2[cpu]; ok {
-			s.Add(cpu)
-		}
+	// Start from the shortest set, the intersection can only be shorter.
+	shortest := slices.MinFunc(sets, func(s1, s2 CPUSet) int {
+		return len(s1.blocks) - len(s2.blocks)
+	})
+
+	s := shortest.Clone()
+	for _, s2 := range sets {
+		s.IntersectWith(s2)
+	}
+
+	return s
+}
+
+// SymmetricDifference returns a new [CPUSet] containing the CPUs present in
+// exactly one of s1 and s2.
+func SymmetricDifference(s1, s2 CPUSet) CPUSet {
+	s := CPUSet{
+		blocks: make([]uint64, max(len(s1.blocks), len(s2.blocks))),
 	}
 
+	copy(s.blocks, s1.blocks)
+	for i, block := range s2.blocks {
+		s.blocks[i] ^= block
+	}
+
+	s.trim()
 	return s
 }
 
 // Union returns a new [CPUSet] containing the CPUs of s1 and s2.
 func Union(s1, s2 CPUSet) CPUSet {
-	var s CPUSet
-	for cpu := range s1.m {
-		s.Add(cpu)
+	return UnionOf(s1, s2)
+}
+
+// UnionOf returns a new [CPUSet] containing the CPUs present in any of the
+// sets.
+func UnionOf(sets ...CPUSet) CPUSet {
+	n := 0
+	for _, s2 := range sets {
+		n = max(n, len(s2.blocks))
 	}
 
-	for cpu := range s2.m {
-		s.Add(cpu)
+	s := CPUSet{
+		blocks: make([]uint64, n),
+	}
+
+	for _, s2 := range sets {
+		for i, block := range s2.blocks {
+			s.blocks[i] |= block
+		}
 	}
 
 	return s
 }
 
-func formatParseError(s string, text string) error {
-	return fmt.Errorf("cpuset: parsing %q: %s", s, text)
+// blockPos returns the index of the block holding cpu and the bit of cpu
+// inside of it.
+func blockPos(cpu uint) (int, uint64) {
+	return int(cpu / blockBitSize), uint64(1) << (cpu % blockBitSize)
 }
@@ -1,8 +1,7 @@
 package cpuset
 
 import (
-	"errors"
-	"maps"
+	"iter"
 	"slices"
 	"testing"
 )
@@ -22,14 +21,11 @@ func TestOf(t *testing.T) {
 		},
 	} {
 		t.Run(params.name, func(t *testing.T) {
-			m := make(map[uint]struct{}, len(params.cpus))
-			for _, cpu := range params.cpus {
-				m[cpu] = struct{}{}
-			}
-
 			s := Of(params.cpus...)
-			if !maps.Equal(m, s.m) {
-				t.Errorf("unexpected map: got %v, want %v", m, s.m)
+			got := s.UnsortedList()
+			slices.Sort(got)
+			if !slices.Equal(got, params.cpus) {
+				t.Errorf("unexpected list: got %v, want %v", got, params.cpus)
 			}
 		})
 	}
@@ -109,6 +105,61 @@ func TestCPUSetDelete(t *testing.T) {
 	}
 }
 
+func TestCPUSetAddRange(t *testing.T) {
+	for _, params := range []struct {
+		name       string
+		s          CPUSet
+		lowerBound uint
+		upperBound uint
+		want       CPUSet
+	}{
+		{
+			name:       "empty negative range",
+			s:          CPUSet{},
+			lowerBound: 3,
+			upperBound: 2,
+			want:       CPUSet{},
+		},
+		{
+			name:       "empty single block",
+			s:          CPUSet{},
+			lowerBound: 1,
+			upperBound: 3,
+			want:       Of(1, 2, 3),
+		},
+		{
+			name:       "not empty across blocks",
+			s:          Of(0),
+			lowerBound: 62,
+			upperBound: 129,
+			want:       Union(Of(0), rangeOf(62, 129)),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			params.s.AddRange(params.lowerBound, params.upperBound)
+			if !params.s.Equal(params.want) {
+				t.Errorf("unexpected cpuset: got %v, want %v", params.s, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetAddSeq(t *testing.T) {
+	s := Of(0, 1)
+	s.AddSeq(slices.Values([]uint{1, 2, 128}))
+	if want := Of(0, 1, 2, 128); !s.Equal(want) {
+		t.Errorf("unexpected cpuset: got %v, want %v", s, want)
+	}
+}
+
+func TestCPUSetDeleteSeq(t *testing.T) {
+	s := Of(0, 1, 2, 128)
+	s.DeleteSeq(slices.Values([]uint{1, 3, 128}))
+	if want := Of(0, 2); !s.Equal(want) {
+		t.Errorf("unexpected cpuset: got %v, want %v", s, want)
+	}
+}
+
 func TestCPUSetContains(t *testing.T) {
 	for _, params := range []struct {
 		name string
@@ -170,6 +221,106 @@ func TestCPUSetUnsortedList(t *testing.T) {
 	}
 }
 
+func TestCPUSetAll(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		want []uint
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+			want: nil,
+		},
+		{
+			name: "not empty",
+			s:    Of(130, 3, 64, 0, 63),
+			want: []uint{0, 3, 63, 64, 130},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := slices.Collect(params.s.All()); !slices.Equal(got, params.want) {
+				t.Errorf("unexpected sequence: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetBackward(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		want []uint
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+			want: nil,
+		},
+		{
+			name: "not empty",
+			s:    Of(130, 3, 64, 0, 63),
+			want: []uint{130, 64, 63, 3, 0},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := slices.Collect(params.s.Backward()); !slices.Equal(got, params.want) {
+				t.Errorf("unexpected sequence: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetRanges(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		want [][2]uint
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+			want: nil,
+		},
+		{
+			name: "not empty",
+			s:    Of(0, 1, 2, 7, 63, 64, 65, 130),
+			want: [][2]uint{{0, 2}, {7, 7}, {63, 65}, {130, 130}},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			var got [][2]uint
+			for lowerBound, upperBound := range params.s.Ranges() {
+				got = append(got, [2]uint{lowerBound, upperBound})
+			}
+
+			if !slices.Equal(got, params.want) {
+				t.Errorf("unexpected ranges: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetIteratorsBreak(t *testing.T) {
+	s := Of(0, 1, 2, 7, 63, 64, 65, 130)
+	for _, seq := range []iter.Seq[uint]{s.All(), s.Backward()} {
+		n := 0
+		for range seq {
+			if n++; n == 2 {
+				break
+			}
+		}
+	}
+
+	for lowerBound := range s.Ranges() {
+		if lowerBound != 0 {
+			t.Errorf("unexpected lower bound: got %d, want 0", lowerBound)
+		}
+
+		break
+	}
+}
+
 func TestCPUSetEqual(t *testing.T) {
 	for _, params := range []struct {
 		name string
@@ -216,6 +367,178 @@ func TestCPUSetEqual(t *testing.T) {
 	}
 }
 
+func TestCPUSetIsSubset(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		s2   CPUSet
+		want bool
+	}{
+		{
+			name: "empty subset of empty",
+			s:    CPUSet{},
+			s2:   CPUSet{},
+			want: true,
+		},
+		{
+			name: "empty subset of not empty",
+			s:    CPUSet{},
+			s2:   Of(0, 1, 2, 3),
+			want: true,
+		},
+		{
+			name: "not empty not subset of empty",
+			s:    Of(0, 1, 2, 3),
+			s2:   CPUSet{},
+			want: false,
+		},
+		{
+			name: "not empty subset of not empty",
+			s:    Of(1, 2),
+			s2:   Of(0, 1, 2, 3),
+			want: true,
+		},
+		{
+			name: "not empty not subset of not empty",
+			s:    Of(1, 128),
+			s2:   Of(0, 1, 2, 3),
+			want: false,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.s.IsSubset(params.s2); got != params.want {
+				t.Errorf("unexpected subset report: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetIsSuperset(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		s2   CPUSet
+		want bool
+	}{
+		{
+			name: "empty superset of empty",
+			s:    CPUSet{},
+			s2:   CPUSet{},
+			want: true,
+		},
+		{
+			name: "empty not superset of not empty",
+			s:    CPUSet{},
+			s2:   Of(0, 1, 2, 3),
+			want: false,
+		},
+		{
+			name: "not empty superset of empty",
+			s:    Of(0, 1, 2, 3),
+			s2:   CPUSet{},
+			want: true,
+		},
+		{
+			name: "not empty superset of not empty",
+			s:    Of(0, 1, 2, 3),
+			s2:   Of(1, 2),
+			want: true,
+		},
+		{
+			name: "not empty not superset of not empty",
+			s:    Of(0, 1, 2, 3),
+			s2:   Of(1, 128),
+			want: false,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.s.IsSuperset(params.s2); got != params.want {
+				t.Errorf("unexpected superset report: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetIsDisjoint(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		s2   CPUSet
+		want bool
+	}{
+		{
+			name: "empty disjoint from empty",
+			s:    CPUSet{},
+			s2:   CPUSet{},
+			want: true,
+		},
+		{
+			name: "empty disjoint from not empty",
+			s:    CPUSet{},
+			s2:   Of(0, 1, 2, 3),
+			want: true,
+		},
+		{
+			name: "not empty disjoint from not empty",
+			s:    Of(0, 1),
+			s2:   Of(2, 128),
+			want: true,
+		},
+		{
+			name: "not empty not disjoint from not empty",
+			s:    Of(0, 1, 128),
+			s2:   Of(2, 128),
+			want: false,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.s.IsDisjoint(params.s2); got != params.want {
+				t.Errorf("unexpected disjoint report: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetOverlaps(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		s2   CPUSet
+		want bool
+	}{
+		{
+			name: "empty not overlapping empty",
+			s:    CPUSet{},
+			s2:   CPUSet{},
+			want: false,
+		},
+		{
+			name: "empty not overlapping not empty",
+			s:    CPUSet{},
+			s2:   Of(0, 1, 2, 3),
+			want: false,
+		},
+		{
+			name: "not empty not overlapping not empty",
+			s:    Of(0, 1),
+			s2:   Of(2, 128),
+			want: false,
+		},
+		{
+			name: "not empty overlapping not empty",
+			s:    Of(0, 1, 128),
+			s2:   Of(2, 128),
+			want: true,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.s.Overlaps(params.s2); got != params.want {
+				t.Errorf("unexpected overlap report: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
 func TestCPUSetClear(t *testing.T) {
 	for _, params := range []struct {
 		name string
@@ -289,32 +612,42 @@ func TestCPUSetLen(t *testing.T) {
 	}
 }
 
-func TestCPUSetString(t *testing.T) {
+func TestCPUSetDifferenceWith(t *testing.T) {
 	for _, params := range []struct {
 		name string
-		s    CPUSet
-		want string
+		s1   CPUSet
+		s2   CPUSet
+		want CPUSet
 	}{
 		{
-			name: "empty",
-			s:    CPUSet{},
-			want: "",
+			name: "empty difference with not empty",
+			s1:   CPUSet{},
+			s2:   Of(0, 1, 2, 3),
+			want: CPUSet{},
 		},
 		{
-			name: "not empty",
-			s:    Of(0, 1, 2, 3),
-			want: "0-3",
+			name: "not empty difference with empty",
+			s1:   Of(0, 1, 2, 3),
+			s2:   CPUSet{},
+			want: Of(0, 1, 2, 3),
+		},
+		{
+			name: "not empty difference with not empty",
+			s1:   Of(0, 1, 2, 128),
+			s2:   Of(0, 128),
+			want: Of(1, 2),
 		},
 	} {
 		t.Run(params.name, func(t *testing.T) {
-			if got := params.s.String(); got != params.want {
-				t.Errorf("unexpected string: got %q, want %q", got, params.want)
+			params.s1.DifferenceWith(params.s2)
+			if !params.s1.Equal(params.want) {
+				t.Errorf("unexpected difference: got %v, want %v", params.s1, params.want)
 			}
 		})
 	}
 }
 
-func TestDifference(t *testing.T) {
+func TestCPUSetIntersectWith(t *testing.T) {
 	for _, params := range []struct {
 		name string
 		s1   CPUSet
@@ -322,27 +655,415 @@ func TestDifference(t *testing.T) {
 		want CPUSet
 	}{
 		{
-			name: "empty difference empty",
-			s1:   CPUSet{},
-			s2:   CPUSet{},
-			want: CPUSet{},
-		},
-		{
-			name: "empty difference not empty",
+			name: "empty intersect with not empty",
 			s1:   CPUSet{},
 			s2:   Of(0, 1, 2, 3),
 			want: CPUSet{},
 		},
 		{
-			name: "not empty difference empty",
+			name: "not empty intersect with empty",
 			s1:   Of(0, 1, 2, 3),
 			s2:   CPUSet{},
-			want: Of(0, 1, 2, 3),
+			want: CPUSet{},
 		},
 		{
-			name: "not empty difference not empty",
-			s1:   Of(0, 1, 2, 3),
-			s2:   Of(0),
+			name: "not empty intersect with not empty",
+			s1:   Of(0, 1, 2, 128),
+			s2:   Of(0, 128, 256),
+			want: Of(0, 128),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			params.s1.IntersectWith(params.s2)
+			if !params.s1.Equal(params.want) {
+				t.Errorf("unexpected intersection: got %v, want %v", params.s1, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetUnionWith(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s1   CPUSet
+		s2   CPUSet
+		want CPUSet
+	}{
+		{
+			name: "empty union with not empty",
+			s1:   CPUSet{},
+			s2:   Of(0, 1, 2, 3),
+			want: Of(0, 1, 2, 3),
+		},
+		{
+			name: "not empty union with empty",
+			s1:   Of(0, 1, 2, 3),
+			s2:   CPUSet{},
+			want: Of(0, 1, 2, 3),
+		},
+		{
+			name: "not empty union with not empty",
+			s1:   Of(0, 1),
+			s2:   Of(1, 256),
+			want: Of(0, 1, 256),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			params.s1.UnionWith(params.s2)
+			if !params.s1.Equal(params.want) {
+				t.Errorf("unexpected union: got %v, want %v", params.s1, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetMin(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		want uint
+		ok   bool
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+		},
+		{
+			name: "not empty",
+			s:    Of(130, 64, 3),
+			want: 3,
+			ok:   true,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got, ok := params.s.Min(); got != params.want || ok != params.ok {
+				t.Errorf("unexpected min: got (%d, %v), want (%d, %v)", got, ok, params.want, params.ok)
+			}
+		})
+	}
+}
+
+func TestCPUSetMax(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		want uint
+		ok   bool
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+		},
+		{
+			name: "not empty",
+			s:    Of(130, 64, 3),
+			want: 130,
+			ok:   true,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got, ok := params.s.Max(); got != params.want || ok != params.ok {
+				t.Errorf("unexpected max: got (%d, %v), want (%d, %v)", got, ok, params.want, params.ok)
+			}
+		})
+	}
+}
+
+func TestCPUSetNth(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		i    int
+		want uint
+		ok   bool
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+			i:    0,
+		},
+		{
+			name: "negative index",
+			s:    Of(3, 64, 65, 130),
+			i:    -1,
+		},
+		{
+			name: "first",
+			s:    Of(3, 64, 65, 130),
+			i:    0,
+			want: 3,
+			ok:   true,
+		},
+		{
+			name: "inside block",
+			s:    Of(3, 64, 65, 130),
+			i:    2,
+			want: 65,
+			ok:   true,
+		},
+		{
+			name: "last",
+			s:    Of(3, 64, 65, 130),
+			i:    3,
+			want: 130,
+			ok:   true,
+		},
+		{
+			name: "out of range",
+			s:    Of(3, 64, 65, 130),
+			i:    4,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got, ok := params.s.Nth(params.i); got != params.want || ok != params.ok {
+				t.Errorf("unexpected CPU: got (%d, %v), want (%d, %v)", got, ok, params.want, params.ok)
+			}
+		})
+	}
+}
+
+func TestCPUSetRank(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		cpu  uint
+		want int
+		ok   bool
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+			cpu:  0,
+		},
+		{
+			name: "not present",
+			s:    Of(3, 64, 65, 130),
+			cpu:  4,
+		},
+		{
+			name: "first",
+			s:    Of(3, 64, 65, 130),
+			cpu:  3,
+			want: 0,
+			ok:   true,
+		},
+		{
+			name: "inside block",
+			s:    Of(3, 64, 65, 130),
+			cpu:  65,
+			want: 2,
+			ok:   true,
+		},
+		{
+			name: "last",
+			s:    Of(3, 64, 65, 130),
+			cpu:  130,
+			want: 3,
+			ok:   true,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got, ok := params.s.Rank(params.cpu); got != params.want || ok != params.ok {
+				t.Errorf("unexpected rank: got (%d, %v), want (%d, %v)", got, ok, params.want, params.ok)
+			}
+		})
+	}
+}
+
+func TestCPUSetNextAfter(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		cpu  uint
+		want uint
+		ok   bool
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+			cpu:  0,
+		},
+		{
+			name: "before first",
+			s:    Of(3, 64, 65, 130),
+			cpu:  0,
+			want: 3,
+			ok:   true,
+		},
+		{
+			name: "present",
+			s:    Of(3, 64, 65, 130),
+			cpu:  64,
+			want: 65,
+			ok:   true,
+		},
+		{
+			name: "across blocks",
+			s:    Of(3, 64, 65, 130),
+			cpu:  65,
+			want: 130,
+			ok:   true,
+		},
+		{
+			name: "last",
+			s:    Of(3, 64, 65, 130),
+			cpu:  130,
+		},
+		{
+			name: "highest CPU",
+			s:    Of(3, 64, 65, 130),
+			cpu:  ^uint(0),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got, ok := params.s.NextAfter(params.cpu); got != params.want || ok != params.ok {
+				t.Errorf("unexpected CPU: got (%d, %v), want (%d, %v)", got, ok, params.want, params.ok)
+			}
+		})
+	}
+}
+
+func TestCPUSetPrevBefore(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		cpu  uint
+		want uint
+		ok   bool
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+			cpu:  10,
+		},
+		{
+			name: "after last",
+			s:    Of(3, 64, 65, 130),
+			cpu:  1000,
+			want: 130,
+			ok:   true,
+		},
+		{
+			name: "present",
+			s:    Of(3, 64, 65, 130),
+			cpu:  65,
+			want: 64,
+			ok:   true,
+		},
+		{
+			name: "across blocks",
+			s:    Of(3, 64, 65, 130),
+			cpu:  64,
+			want: 3,
+			ok:   true,
+		},
+		{
+			name: "first",
+			s:    Of(3, 64, 65, 130),
+			cpu:  3,
+		},
+		{
+			name: "lowest CPU",
+			s:    Of(3, 64, 65, 130),
+			cpu:  0,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got, ok := params.s.PrevBefore(params.cpu); got != params.want || ok != params.ok {
+				t.Errorf("unexpected CPU: got (%d, %v), want (%d, %v)", got, ok, params.want, params.ok)
+			}
+		})
+	}
+}
+
+func TestCPUSetString(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		want string
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+			want: "",
+		},
+		{
+			name: "not empty",
+			s:    Of(0, 1, 2, 3),
+			want: "0-3",
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.s.String(); got != params.want {
+				t.Errorf("unexpected string: got %q, want %q", got, params.want)
+			}
+		})
+	}
+}
+
+func TestComplement(t *testing.T) {
+	for _, params := range []struct {
+		name     string
+		s        CPUSet
+		universe CPUSet
+		want     CPUSet
+	}{
+		{
+			name:     "empty complement in empty",
+			s:        CPUSet{},
+			universe: CPUSet{},
+			want:     CPUSet{},
+		},
+		{
+			name:     "empty complement in not empty",
+			s:        CPUSet{},
+			universe: Of(0, 1, 2, 3),
+			want:     Of(0, 1, 2, 3),
+		},
+		{
+			name:     "not empty complement in not empty",
+			s:        Of(0, 2, 128),
+			universe: Of(0, 1, 2, 3),
+			want:     Of(1, 3),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := Complement(params.s, params.universe); !got.Equal(params.want) {
+				t.Errorf("unexpected complement: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestDifference(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s1   CPUSet
+		s2   CPUSet
+		want CPUSet
+	}{
+		{
+			name: "empty difference empty",
+			s1:   CPUSet{},
+			s2:   CPUSet{},
+			want: CPUSet{},
+		},
+		{
+			name: "empty difference not empty",
+			s1:   CPUSet{},
+			s2:   Of(0, 1, 2, 3),
+			want: CPUSet{},
+		},
+		{
+			name: "not empty difference empty",
+			s1:   Of(0, 1, 2, 3),
+			s2:   CPUSet{},
+			want: Of(0, 1, 2, 3),
+		},
+		{
+			name: "not empty difference not empty",
+			s1:   Of(0, 1, 2, 3),
+			s2:   Of(0),
 			want: Of(1, 2, 3),
 		},
 	} {
@@ -394,6 +1115,76 @@ func TestIntersection(t *testing.T) {
 	}
 }
 
+func TestIntersectionOf(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		sets []CPUSet
+		want CPUSet
+	}{
+		{
+			name: "no set",
+			sets: nil,
+			want: CPUSet{},
+		},
+		{
+			name: "one set",
+			sets: []CPUSet{Of(0, 1, 2, 3)},
+			want: Of(0, 1, 2, 3),
+		},
+		{
+			name: "many sets",
+			sets: []CPUSet{Of(0, 1, 2, 3, 256), Of(1, 2, 3, 256), Of(0, 2, 3)},
+			want: Of(2, 3),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := IntersectionOf(params.sets...); !got.Equal(params.want) {
+				t.Errorf("unexpected intersection: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestSymmetricDifference(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s1   CPUSet
+		s2   CPUSet
+		want CPUSet
+	}{
+		{
+			name: "empty symmetric difference empty",
+			s1:   CPUSet{},
+			s2:   CPUSet{},
+			want: CPUSet{},
+		},
+		{
+			name: "empty symmetric difference not empty",
+			s1:   CPUSet{},
+			s2:   Of(0, 1, 2, 3),
+			want: Of(0, 1, 2, 3),
+		},
+		{
+			name: "not empty symmetric difference empty",
+			s1:   Of(0, 1, 2, 3),
+			s2:   CPUSet{},
+			want: Of(0, 1, 2, 3),
+		},
+		{
+			name: "not empty symmetric difference not empty",
+			s1:   Of(0, 1, 256),
+			s2:   Of(1, 2, 256),
+			want: Of(0, 2),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := SymmetricDifference(params.s1, params.s2); !got.Equal(params.want) {
+				t.Errorf("unexpected symmetric difference: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
 func TestUnion(t *testing.T) {
 	for _, params := range []struct {
 		name string
@@ -434,9 +1225,244 @@ func TestUnion(t *testing.T) {
 	}
 }
 
-func TestFormatParseError(t *testing.T) {
-	want := errors.New(`cpuset: parsing "s": error`)
-	if got := formatParseError("s", "error"); got.Error() != want.Error() {
-		t.Errorf("unexpected error: got %v, want %v", got, want)
+func TestUnionOf(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		sets []CPUSet
+		want CPUSet
+	}{
+		{
+			name: "no set",
+			sets: nil,
+			want: CPUSet{},
+		},
+		{
+			name: "one set",
+			sets: []CPUSet{Of(0, 1, 2, 3)},
+			want: Of(0, 1, 2, 3),
+		},
+		{
+			name: "many sets",
+			sets: []CPUSet{Of(0, 1), CPUSet{}, Of(256), Of(1, 2)},
+			want: Of(0, 1, 2, 256),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := UnionOf(params.sets...); !got.Equal(params.want) {
+				t.Errorf("unexpected union: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetBlocks(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		fn   func() CPUSet
+		want []uint64
+	}{
+		{
+			name: "delete last",
+			fn: func() CPUSet {
+				s := Of(1, 64, 130)
+				s.Delete(130)
+				return s
+			},
+			want: []uint64{1 << 1, 1 << 0},
+		},
+		{
+			name: "difference trimmed",
+			fn: func() CPUSet {
+				return Difference(Of(0, 128), Of(128))
+			},
+			want: []uint64{1 << 0},
+		},
+		{
+			name: "intersection trimmed",
+			fn: func() CPUSet {
+				return Intersection(Of(0, 128), Of(0, 129))
+			},
+			want: []uint64{1 << 0},
+		},
+		{
+			name: "range across blocks",
+			fn: func() CPUSet {
+				var s CPUSet
+				s.AddRange(60, 130)
+				return s
+			},
+			want: []uint64{0xf << 60, ^uint64(0), 0x7},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.fn(); !slices.Equal(got.blocks, params.want) {
+				t.Errorf("unexpected blocks: got %#x, want %#x", got.blocks, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetCopy(t *testing.T) {
+	for _, params := range []struct {
+		name   string
+		update func(s *CPUSet)
+		want   CPUSet
+		copy   CPUSet
+	}{
+		{"Add", func(s *CPUSet) { s.Add(3) }, Of(1, 2, 3, 70), Of(1, 2, 3, 70)},
+		{"AddGrow", func(s *CPUSet) { s.Add(130) }, Of(1, 2, 70, 130), Of(1, 2, 70)},
+		{"Delete", func(s *CPUSet) { s.Delete(2) }, Of(1, 70), Of(1, 70)},
+		{"DeleteShrink", func(s *CPUSet) { s.Delete(70) }, Of(1, 2), Of(1, 2, 70)},
+		{"Clear", func(s *CPUSet) { s.Clear() }, Of(), Of(1, 2, 70)},
+		{"ClearAdd", func(s *CPUSet) { s.Clear(); s.Add(5) }, Of(5), Of(1, 2, 70)},
+		{"DifferenceWith", func(s *CPUSet) { s.DifferenceWith(Of(2)) }, Of(1, 70), Of(1, 70)},
+		{"DifferenceWithShrink", func(s *CPUSet) { s.DifferenceWith(Of(70)) }, Of(1, 2), Of(1, 2, 70)},
+		{"IntersectWith", func(s *CPUSet) { s.IntersectWith(Of(1, 70)) }, Of(1, 70), Of(1, 70)},
+		{"IntersectWithShrink", func(s *CPUSet) { s.IntersectWith(Of(1)) }, Of(1), Of(1, 2, 70)},
+		{"UnionWithGrow", func(s *CPUSet) { s.UnionWith(Of(130)) }, Of(1, 2, 70, 130), Of(1, 2, 70)},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			s := Of(1, 2, 70)
+			c := s
+			params.update(&s)
+			switch {
+			case !s.Equal(params.want):
+				t.Errorf("unexpected set: got %v, want %v", s, params.want)
+			case !c.Equal(params.copy):
+				t.Errorf("unexpected copy: got %v, want %v", c, params.copy)
+			}
+		})
+	}
+}
+
+func TestCPUSetCopyGrow(t *testing.T) {
+	// s and c share a bitset with spare capacity: growing one must not
+	// write into the block the other grows into.
+	s := Of(1, 64)
+	s.Add(128)
+	c := s
+	s.Add(192)
+	c.Add(200)
+	switch {
+	case !s.Equal(Of(1, 64, 128, 192)):
+		t.Errorf("unexpected set: got %v, want %v", s, Of(1, 64, 128, 192))
+	case !c.Equal(Of(1, 64, 128, 200)):
+		t.Errorf("unexpected copy: got %v, want %v", c, Of(1, 64, 128, 200))
+	}
+}
+
+// rangeOf returns a new CPUSet containing the CPUs from lowerBound to
+// upperBound inclusive, one at a time.
+func rangeOf(lowerBound, upperBound uint) CPUSet {
+	var s CPUSet
+	for cpu := lowerBound; cpu <= upperBound; cpu++ {
+		s.Add(cpu)
+	}
+
+	return s
+}
+
+// benchmarkCPUSets returns two overlapping cpusets spanning 384 CPUs, the
+// size of a large multi-socket host.
+func benchmarkCPUSets() (CPUSet, CPUSet) {
+	var s1, s2 CPUSet
+	for cpu := range uint(384) {
+		if cpu%3 != 0 {
+			s1.Add(cpu)
+		}
+
+		if cpu%5 != 0 {
+			s2.Add(cpu)
+		}
+	}
+
+	return s1, s2
+}
+
+func BenchmarkDifference(b *testing.B) {
+	s1, s2 := benchmarkCPUSets()
+	for range b.N {
+		Difference(s1, s2)
+	}
+}
+
+func BenchmarkIntersection(b *testing.B) {
+	s1, s2 := benchmarkCPUSets()
+	for range b.N {
+		Intersection(s1, s2)
+	}
+}
+
+func BenchmarkUnion(b *testing.B) {
+	s1, s2 := benchmarkCPUSets()
+	for range b.N {
+		Union(s1, s2)
+	}
+}
+
+func BenchmarkUnionOf(b *testing.B) {
+	sets := make([]CPUSet, 200)
+	for i := range sets {
+		sets[i] = Of(uint(i), uint(i+184))
+	}
+
+	for range b.N {
+		UnionOf(sets...)
+	}
+}
+
+func BenchmarkCPUSetUnionWith(b *testing.B) {
+	s1, s2 := benchmarkCPUSets()
+	for range b.N {
+		s1.UnionWith(s2)
+	}
+}
+
+func BenchmarkCPUSetEqual(b *testing.B) {
+	s1, _ := benchmarkCPUSets()
+	s2 := s1.Clone()
+	for range b.N {
+		s1.Equal(s2)
+	}
+}
+
+func BenchmarkCPUSetLen(b *testing.B) {
+	s, _ := benchmarkCPUSets()
+	for range b.N {
+		s.Len()
+	}
+}
+
+func BenchmarkListString(b *testing.B) {
+	s, _ := benchmarkCPUSets()
+	b.ReportAllocs()
+	for range b.N {
+		s.ListString()
+	}
+}
+
+func BenchmarkCPUSetAppendList(b *testing.B) {
+	s, _ := benchmarkCPUSets()
+	buf := make([]byte, 0, 4096)
+	b.ReportAllocs()
+	for range b.N {
+		s.AppendList(buf[:0])
+	}
+}
+
+func BenchmarkMaskString(b *testing.B) {
+	s, _ := benchmarkCPUSets()
+	b.ReportAllocs()
+	for range b.N {
+		s.MaskString()
+	}
+}
+
+func BenchmarkCPUSetAppendMask(b *testing.B) {
+	s, _ := benchmarkCPUSets()
+	buf := make([]byte, 0, 4096)
+	b.ReportAllocs()
+	for range b.N {
+		s.AppendMask(buf[:0])
 	}
 }
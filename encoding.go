@@ -0,0 +1,198 @@
+package cpuset
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// Binary encoding of a CPUSet: a version byte, a layout byte and the CPUs,
+// laid out either as ranges or as a bitmap, whichever is the shortest.
+const (
+	binaryVersion = 1
+
+	// binaryRanges lays out each range of contiguous CPUs as two uvarints:
+	// the gap since the end of the previous range (or CPU 0) and the
+	// length of the range minus one.
+	binaryRanges = 0
+
+	// binaryBitmap lays out the CPUs as a little-endian bitmap, CPU n being
+	// the bit n%8 of byte n/8.
+	binaryBitmap = 1
+)
+
+// MarshalText implements the [encoding.TextMarshaler] interface. It encodes s
+// into a list string.
+func (s CPUSet) MarshalText() ([]byte, error) {
+	return s.AppendList(nil), nil
+}
+
+// UnmarshalText implements the [encoding.TextUnmarshaler] interface. It
+// decodes a list string into s, as [ParseList] does.
+func (s *CPUSet) UnmarshalText(text []byte) error {
+	cset, err := ParseList(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = cset
+	return nil
+}
+
+// AppendBinary appends the binary encoding of s to b. It follows the
+// encoding.BinaryAppender interface introduced in Go 1.24.
+func (s CPUSet) AppendBinary(b []byte) ([]byte, error) {
+	var (
+		rangesSize = 0
+		end        = uint(0)
+	)
+
+	for lowerBound, upperBound := range s.Ranges() {
+		rangesSize += uvarintSize(uint64(lowerBound-end)) + uvarintSize(uint64(upperBound-lowerBound))
+		end = upperBound + 1
+	}
+
+	bitmapSize := 0
+	if len(s.blocks) > 0 {
+		bitmapSize = int(s.max()/8) + 1
+	}
+
+	if bitmapSize < rangesSize {
+		b = append(b, binaryVersion, binaryBitmap)
+		for i := range bitmapSize {
+			b = append(b, byte(s.blocks[i/8]>>(i%8*8)))
+		}
+
+		return b, nil
+	}
+
+	b = append(b, binaryVersion, binaryRanges)
+	end = 0
+	for lowerBound, upperBound := range s.Ranges() {
+		b = binary.AppendUvarint(b, uint64(lowerBound-end))
+		b = binary.AppendUvarint(b, uint64(upperBound-lowerBound))
+		end = upperBound + 1
+	}
+
+	return b, nil
+}
+
+// MarshalBinary implements the [encoding.BinaryMarshaler] interface.
+func (s CPUSet) MarshalBinary() ([]byte, error) {
+	return s.AppendBinary(nil)
+}
+
+// UnmarshalBinary implements the [encoding.BinaryUnmarshaler] interface. CPUs
+// must be lower than [DefaultLimit].
+func (s *CPUSet) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errors.New("cpuset: decoding binary: missing header")
+	}
+
+	if data[0] != binaryVersion {
+		return fmt.Errorf("cpuset: decoding binary: unsupported version %d", data[0])
+	}
+
+	var cset CPUSet
+
+	switch layout, data := data[1], data[2:]; layout {
+	case binaryRanges:
+		end := uint64(0)
+		for len(data) > 0 {
+			gap, n := binary.Uvarint(data)
+			if n <= 0 {
+				return errors.New("cpuset: decoding binary: invalid range gap")
+			}
+
+			data = data[n:]
+
+			length, n := binary.Uvarint(data)
+			if n <= 0 {
+				return errors.New("cpuset: decoding binary: invalid range length")
+			}
+
+			data = data[n:]
+
+			lowerBound, carry := bits.Add64(end, gap, 0)
+			upperBound, carry := bits.Add64(lowerBound, length, carry)
+			if carry != 0 || upperBound >= DefaultLimit {
+				// Saturate the offending CPU if it cannot be represented.
+				cpu := uint64(^uint(0))
+				if carry == 0 {
+					cpu = min(cpu, upperBound)
+				}
+
+				return fmt.Errorf("cpuset: decoding binary: %w", &RangeError{CPU: uint(cpu), Limit: DefaultLimit})
+			}
+
+			cset.AddRange(uint(lowerBound), uint(upperBound))
+			end = upperBound + 1
+		}
+
+	case binaryBitmap:
+		if len(data)*8 > DefaultLimit {
+			for i, c := range data[DefaultLimit/8:] {
+				if c != 0 {
+					cpu := uint(DefaultLimit + i*8 + bits.Len8(c) - 1)
+					return fmt.Errorf("cpuset: decoding binary: %w", &RangeError{CPU: cpu, Limit: DefaultLimit})
+				}
+			}
+
+			data = data[:DefaultLimit/8]
+		}
+
+		cset.blocks = make([]uint64, (len(data)+7)/8)
+		for i, c := range data {
+			cset.blocks[i/8] |= uint64(c) << (i % 8 * 8)
+		}
+
+		cset.trim()
+
+	default:
+		return fmt.Errorf("cpuset: decoding binary: unsupported layout %d", layout)
+	}
+
+	*s = cset
+	return nil
+}
+
+// A JSONArray is a [CPUSet] encoded in JSON as an array of CPUs, in
+// ascending order, rather than as a list string.
+type JSONArray struct {
+	CPUSet
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (a JSONArray) MarshalJSON() ([]byte, error) {
+	return json.Marshal(slices.AppendSeq(make([]uint, 0, a.Len()), a.All()))
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface. CPUs must be
+// lower than [DefaultLimit].
+func (a *JSONArray) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	var cpus []uint
+	if err := json.Unmarshal(b, &cpus); err != nil {
+		return err
+	}
+
+	for _, cpu := range cpus {
+		if cpu >= DefaultLimit {
+			return fmt.Errorf("cpuset: decoding JSON array: %w", &RangeError{CPU: cpu, Limit: DefaultLimit})
+		}
+	}
+
+	a.CPUSet = Of(cpus...)
+	return nil
+}
+
+// uvarintSize returns the number of bytes of the uvarint encoding of x.
+func uvarintSize(x uint64) int {
+	return max(1, (bits.Len64(x)+6)/7)
+}
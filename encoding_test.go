@@ -0,0 +1,363 @@
+package cpuset
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCPUSetMarshalText(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		v    any
+		want string
+	}{
+		{
+			name: "empty",
+			v:    struct{ CPUs CPUSet }{},
+			want: `{"CPUs":""}`,
+		},
+		{
+			name: "not empty value",
+			v:    struct{ CPUs CPUSet }{Of(0, 1, 2, 3, 8)},
+			want: `{"CPUs":"0-3,8"}`,
+		},
+		{
+			name: "not empty pointer",
+			v:    struct{ CPUs *CPUSet }{&CPUSet{}},
+			want: `{"CPUs":""}`,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			got, err := json.Marshal(params.v)
+			switch {
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case string(got) != params.want:
+				t.Errorf("unexpected JSON: got %s, want %s", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetUnmarshalText(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		data string
+		want CPUSet
+		err  bool
+	}{
+		{
+			name: "invalid list string",
+			data: `{"CPUs":"a"}`,
+			err:  true,
+		},
+		{
+			name: "not a string",
+			data: `{"CPUs":0}`,
+			err:  true,
+		},
+		{
+			name: "empty",
+			data: `{"CPUs":""}`,
+			want: CPUSet{},
+		},
+		{
+			name: "not empty",
+			data: `{"CPUs":"0-3,8"}`,
+			want: Of(0, 1, 2, 3, 8),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			var v struct{ CPUs CPUSet }
+			switch err := json.Unmarshal([]byte(params.data), &v); {
+			case err == nil && params.err:
+				t.Error("expected error")
+			case err != nil && !params.err:
+				t.Errorf("unexpected error: %v", err)
+			case err == nil && !v.CPUs.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", v.CPUs, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetUnmarshalTextParseError(t *testing.T) {
+	var s CPUSet
+	if err := s.UnmarshalText([]byte("0-3,a")); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestJSONArrayMarshalJSON(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		a    JSONArray
+		want string
+	}{
+		{
+			name: "empty",
+			a:    JSONArray{},
+			want: `[]`,
+		},
+		{
+			name: "not empty",
+			a:    JSONArray{Of(8, 0, 1, 2, 3)},
+			want: `[0,1,2,3,8]`,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			got, err := json.Marshal(params.a)
+			switch {
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case string(got) != params.want:
+				t.Errorf("unexpected JSON: got %s, want %s", got, params.want)
+			}
+		})
+	}
+}
+
+func TestJSONArrayUnmarshalJSON(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		data string
+		want CPUSet
+		err  bool
+	}{
+		{
+			name: "not an array",
+			data: `"0-3"`,
+			err:  true,
+		},
+		{
+			name: "negative CPU",
+			data: `[-1]`,
+			err:  true,
+		},
+		{
+			name: "CPU out of range",
+			data: `[8192]`,
+			err:  true,
+		},
+		{
+			name: "null",
+			data: `null`,
+			want: Of(0),
+		},
+		{
+			name: "empty",
+			data: `[]`,
+			want: CPUSet{},
+		},
+		{
+			name: "not empty",
+			data: `[8,0,1,2,3,3]`,
+			want: Of(0, 1, 2, 3, 8),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			a := JSONArray{Of(0)}
+			switch err := json.Unmarshal([]byte(params.data), &a); {
+			case err == nil && params.err:
+				t.Error("expected error")
+			case err != nil && !params.err:
+				t.Errorf("unexpected error: %v", err)
+			case err == nil && !a.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", a.CPUSet, params.want)
+			}
+		})
+	}
+}
+
+func TestJSONArrayUnmarshalJSONRangeError(t *testing.T) {
+	var (
+		a        JSONArray
+		rangeErr *RangeError
+	)
+
+	switch err := json.Unmarshal([]byte(`[0,8192]`), &a); {
+	case !errors.As(err, &rangeErr):
+		t.Errorf("unexpected error type: %T", err)
+	case *rangeErr != RangeError{CPU: 8192, Limit: DefaultLimit}:
+		t.Errorf("unexpected range error: %v", rangeErr)
+	}
+}
+
+func TestCPUSetMarshalBinary(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		want []byte
+	}{
+		{
+			name: "empty",
+			s:    CPUSet{},
+			want: []byte{binaryVersion, binaryRanges},
+		},
+		{
+			name: "ranges",
+			s:    Of(0, 1, 2, 3, 300),
+			want: []byte{binaryVersion, binaryRanges, 0, 3, 0xa8, 0x02, 0},
+		},
+		{
+			name: "bitmap",
+			s:    Of(0, 2, 4, 6, 9, 11),
+			want: []byte{binaryVersion, binaryBitmap, 0x55, 0x0a},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			got, err := params.s.MarshalBinary()
+			switch {
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case !slices.Equal(got, params.want):
+				t.Errorf("unexpected binary: got %#v, want %#v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetAppendBinary(t *testing.T) {
+	s := Of(0, 1, 2, 3)
+	want := []byte{0xff, binaryVersion, binaryBitmap, 0x0f}
+	if got, err := s.AppendBinary([]byte{0xff}); err != nil || !slices.Equal(got, want) {
+		t.Errorf("unexpected binary: got %#v (error: %v), want %#v", got, err, want)
+	}
+}
+
+func TestCPUSetUnmarshalBinary(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		data []byte
+		want CPUSet
+		err  bool
+	}{
+		{
+			name: "missing header",
+			data: []byte{binaryVersion},
+			err:  true,
+		},
+		{
+			name: "unsupported version",
+			data: []byte{0, binaryRanges},
+			err:  true,
+		},
+		{
+			name: "unsupported layout",
+			data: []byte{binaryVersion, 0xff},
+			err:  true,
+		},
+		{
+			name: "invalid range gap",
+			data: []byte{binaryVersion, binaryRanges, 0x80},
+			err:  true,
+		},
+		{
+			name: "invalid range length",
+			data: []byte{binaryVersion, binaryRanges, 0},
+			err:  true,
+		},
+		{
+			name: "range out of range",
+			data: []byte{binaryVersion, binaryRanges, 0, 0x80, 0x40},
+			err:  true,
+		},
+		{
+			name: "range overflowing",
+			data: []byte{binaryVersion, binaryRanges, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 1},
+			err:  true,
+		},
+		{
+			name: "bitmap out of range",
+			data: append(append([]byte{binaryVersion, binaryBitmap}, make([]byte, DefaultLimit/8)...), 1),
+			err:  true,
+		},
+		{
+			name: "empty",
+			data: []byte{binaryVersion, binaryRanges},
+			want: CPUSet{},
+		},
+		{
+			name: "ranges",
+			data: []byte{binaryVersion, binaryRanges, 0, 3, 0xa8, 0x02, 0},
+			want: Of(0, 1, 2, 3, 300),
+		},
+		{
+			name: "bitmap",
+			data: []byte{binaryVersion, binaryBitmap, 0x55, 0x0a, 0, 0},
+			want: Of(0, 2, 4, 6, 9, 11),
+		},
+		{
+			name: "bitmap with trailing zero bytes up to the limit",
+			data: append([]byte{binaryVersion, binaryBitmap, 1}, make([]byte, DefaultLimit/8)...),
+			want: Of(0),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			var got CPUSet
+			switch err := got.UnmarshalBinary(params.data); {
+			case err == nil && params.err:
+				t.Error("expected error")
+			case err != nil && !params.err:
+				t.Errorf("unexpected error: %v", err)
+			case err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func FuzzCPUSetBinaryRoundTrip(f *testing.F) {
+	for _, s := range []string{"", "0", "0-3,8", "0-4,9", "0-2,7,12-14", "0-1023:2/256", "0-8191", "1,3,5,7,9,11,8191"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		want, err := ParseList(s)
+		if err != nil {
+			t.Skip()
+		}
+
+		data, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var got CPUSet
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.ListString() != want.ListString() {
+			t.Errorf("unexpected list string: got %q, want %q", got.ListString(), want.ListString())
+		}
+	})
+}
+
+func FuzzCPUSetUnmarshalBinary(f *testing.F) {
+	for _, data := range [][]byte{
+		{binaryVersion, binaryRanges},
+		{binaryVersion, binaryRanges, 0, 3, 0xa8, 0x02, 0},
+		{binaryVersion, binaryBitmap, 0x55, 0x0a},
+	} {
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var want CPUSet
+		if err := want.UnmarshalBinary(data); err != nil {
+			t.Skip()
+		}
+
+		got, err := ParseList(want.ListString())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !got.Equal(want) {
+			t.Errorf("unexpected cpuset: got %v, want %v", got, want)
+		}
+	})
+}
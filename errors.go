@@ -0,0 +1,121 @@
+package cpuset
+
+import (
+	"fmt"
+)
+
+// An ErrorKind describes the cause of a [ParseError]. Each kind is also an
+// error, so that it can be matched with [errors.Is].
+type ErrorKind int
+
+const (
+	ErrInvalidElement      ErrorKind = iota + 1 // element not a CPU or a range
+	ErrInvalidLowerBound                        // lower bound of a range not a CPU
+	ErrInvalidUpperBound                        // upper bound of a range not a CPU
+	ErrNegativeRange                            // upper bound of a range lower than its lower bound
+	ErrInvalidPattern                           // stride pattern of a range malformed
+	ErrInvalidUsedSize                          // used size of a stride pattern not a number
+	ErrInvalidGroupSize                         // group size of a stride pattern not a number
+	ErrInvalidWord                              // word of a mask not a hexadecimal number of its size
+	ErrTooManyWords                             // mask too long for its CPUs to be represented
+	ErrOutOfRange                               // CPU exceeding the limit set for parsing
+	ErrWidthMismatch                            // mask not of the width set for parsing
+	ErrUnsupportedWordSize                      // word size of the mask options neither 32 nor 64 bits
+)
+
+var errorKindTexts = [...]string{
+	ErrInvalidElement:      "invalid element",
+	ErrInvalidLowerBound:   "invalid lower bound",
+	ErrInvalidUpperBound:   "invalid upper bound",
+	ErrNegativeRange:       "negative range",
+	ErrInvalidPattern:      "invalid pattern",
+	ErrInvalidUsedSize:     "invalid used size",
+	ErrInvalidGroupSize:    "invalid group size",
+	ErrInvalidWord:         "invalid word",
+	ErrTooManyWords:        "offset value out of range",
+	ErrOutOfRange:          "CPU out of range",
+	ErrWidthMismatch:       "mask width mismatch",
+	ErrUnsupportedWordSize: "unsupported word size",
+}
+
+func (k ErrorKind) Error() string {
+	if k <= 0 || int(k) >= len(errorKindTexts) {
+		return fmt.Sprintf("cpuset: unknown error kind %d", int(k))
+	}
+
+	return errorKindTexts[k]
+}
+
+// A ParseError records a failed attempt to decode a cpuset string.
+type ParseError struct {
+	Input   string    // the input being decoded
+	Format  Format    // the format of the input
+	Offset  int       // the byte offset of Element in Input
+	Element string    // the offending element (list) or word (mask)
+	Part    string    // the offending part of Element, such as a range bound
+	Kind    ErrorKind // the cause of the error
+	Err     error     // the underlying error, if any
+
+	wordBitSize uint // the size of the words of a mask, if known
+}
+
+func (e *ParseError) Error() string {
+	var text string
+
+	switch e.Kind {
+	case ErrInvalidElement, ErrNegativeRange:
+		text = fmt.Sprintf("%v %q", e.Kind, e.Element)
+	case ErrInvalidWord:
+		text = fmt.Sprintf("%v %q", e.Kind, e.Element)
+		if e.wordBitSize > 0 {
+			text = fmt.Sprintf("invalid %d-bit word %q", e.wordBitSize, e.Element)
+		}
+	case ErrUnsupportedWordSize:
+		text = fmt.Sprintf("%v %d", e.Kind, e.wordBitSize)
+	case ErrInvalidLowerBound, ErrInvalidUpperBound, ErrInvalidPattern, ErrInvalidUsedSize, ErrInvalidGroupSize:
+		text = fmt.Sprintf("%v %q in range %q", e.Kind, e.Part, e.Element)
+	default:
+		text = e.Kind.Error()
+	}
+
+	if e.Err != nil {
+		text = e.Err.Error()
+	}
+
+	return fmt.Sprintf("cpuset: parsing %q: %s", e.Input, text)
+}
+
+func (e *ParseError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+
+	return []error{e.Kind, e.Err}
+}
+
+// with returns a copy of e caused by kind. It is used to fill the
+// element-specific fields of e once the input is known to be invalid.
+func (e ParseError) with(kind ErrorKind, part string, err error) *ParseError {
+	e.Kind, e.Part, e.Err = kind, part, err
+	return &e
+}
+
+// A RangeError records a CPU exceeding the limit set for parsing.
+type RangeError struct {
+	CPU   uint // the offending CPU
+	Limit uint // the limit in effect
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("CPU %d out of range [0, %d)", e.CPU, e.Limit)
+}
+
+// A WidthError records a mask not of the width set for parsing.
+type WidthError struct {
+	Width uint // the width of the mask, in bits
+	Want  uint // the width expected, in bits
+}
+
+func (e *WidthError) Error() string {
+	return fmt.Sprintf("mask width of %d bits, want %d", e.Width, e.Want)
+}
@@ -0,0 +1,128 @@
+package cpuset
+
+import (
+	"errors"
+	"testing"
+)
+
+// equalParseError reports whether err is a [ParseError] equal to want, the
+// underlying errors being compared by their text.
+func equalParseError(err error, want *ParseError) bool {
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		return false
+	}
+
+	got, wantErr := *perr, *want
+	if (got.Err == nil) != (wantErr.Err == nil) || got.Err != nil && got.Err.Error() != wantErr.Err.Error() {
+		return false
+	}
+
+	got.Err, wantErr.Err = nil, nil
+	return got == wantErr && perr.Error() == want.Error()
+}
+
+func TestErrorKindError(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		kind ErrorKind
+		want string
+	}{
+		{
+			name: "invalid element",
+			kind: ErrInvalidElement,
+			want: "invalid element",
+		},
+		{
+			name: "CPU out of range",
+			kind: ErrOutOfRange,
+			want: "CPU out of range",
+		},
+		{
+			name: "unknown",
+			kind: 0,
+			want: "cpuset: unknown error kind 0",
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.kind.Error(); got != params.want {
+				t.Errorf("unexpected error: got %q, want %q", got, params.want)
+			}
+		})
+	}
+}
+
+func TestParseErrorError(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		err  *ParseError
+		want string
+	}{
+		{
+			name: "invalid element",
+			err:  &ParseError{Input: "0,a", Offset: 2, Element: "a", Part: "a", Kind: ErrInvalidElement},
+			want: `cpuset: parsing "0,a": invalid element "a"`,
+		},
+		{
+			name: "invalid lower bound",
+			err:  &ParseError{Input: "a-1", Element: "a-1", Part: "a", Kind: ErrInvalidLowerBound},
+			want: `cpuset: parsing "a-1": invalid lower bound "a" in range "a-1"`,
+		},
+		{
+			name: "invalid 32-bit word",
+			err:  &ParseError{Input: "x", Format: MaskFormat, Element: "x", Part: "x", Kind: ErrInvalidWord, wordBitSize: 32},
+			want: `cpuset: parsing "x": invalid 32-bit word "x"`,
+		},
+		{
+			name: "invalid word",
+			err:  &ParseError{Input: "x", Format: MaskFormat, Element: "x", Part: "x", Kind: ErrInvalidWord},
+			want: `cpuset: parsing "x": invalid word "x"`,
+		},
+		{
+			name: "offset value out of range",
+			err:  &ParseError{Input: "0,0", Format: MaskFormat, Kind: ErrTooManyWords},
+			want: `cpuset: parsing "0,0": offset value out of range`,
+		},
+		{
+			name: "CPU out of range",
+			err:  &ParseError{Input: "4", Element: "4", Part: "4", Kind: ErrOutOfRange, Err: &RangeError{CPU: 4, Limit: 4}},
+			want: `cpuset: parsing "4": CPU 4 out of range [0, 4)`,
+		},
+		{
+			name: "unsupported word size",
+			err:  &ParseError{Input: "ff", Format: MaskFormat, Kind: ErrUnsupportedWordSize, wordBitSize: 16},
+			want: `cpuset: parsing "ff": unsupported word size 16`,
+		},
+		{
+			name: "mask width mismatch",
+			err:  &ParseError{Input: "3", Format: MaskFormat, Kind: ErrWidthMismatch, Err: &WidthError{Width: 4, Want: 64}},
+			want: `cpuset: parsing "3": mask width of 4 bits, want 64`,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.err.Error(); got != params.want {
+				t.Errorf("unexpected error: got %q, want %q", got, params.want)
+			}
+		})
+	}
+}
+
+func TestParseErrorUnwrap(t *testing.T) {
+	_, err := ParseList("0-3,8192")
+
+	var rangeErr *RangeError
+	switch {
+	case !errors.Is(err, ErrOutOfRange):
+		t.Errorf("unexpected error kind: %v", err)
+	case errors.Is(err, ErrInvalidElement):
+		t.Errorf("unexpected error kind: %v", err)
+	case !errors.As(err, &rangeErr):
+		t.Errorf("unexpected error type: %T", err)
+	case *rangeErr != RangeError{CPU: 8192, Limit: DefaultLimit}:
+		t.Errorf("unexpected range error: %v", rangeErr)
+	}
+
+	if _, err := ParseMask("0,x"); !errors.Is(err, ErrInvalidWord) {
+		t.Errorf("unexpected error kind: %v", err)
+	}
+}
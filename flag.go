@@ -0,0 +1,83 @@
+package cpuset
+
+// Set implements the [flag.Value] interface. It decodes a list string into s,
+// as [ParseList] does.
+func (s *CPUSet) Set(value string) error {
+	return s.UnmarshalText([]byte(value))
+}
+
+// Type returns the name of the type of s, as expected by the
+// [github.com/spf13/pflag] package.
+//
+// [github.com/spf13/pflag]: https://pkg.go.dev/github.com/spf13/pflag#Value
+func (s *CPUSet) Type() string {
+	return "cpuset"
+}
+
+// A Flag is a [flag.Value] decoding and encoding a [CPUSet] in a given
+// format. It is also usable with the [github.com/spf13/pflag] package.
+//
+// [github.com/spf13/pflag]: https://pkg.go.dev/github.com/spf13/pflag#Value
+type Flag struct {
+	cset   *CPUSet
+	format Format
+}
+
+// NewFlag returns a new [Flag] storing its value in p, encoded in format.
+func NewFlag(p *CPUSet, format Format) *Flag {
+	return &Flag{
+		cset:   p,
+		format: format,
+	}
+}
+
+// Set implements the [flag.Value] interface.
+func (f *Flag) Set(value string) error {
+	var (
+		cset CPUSet
+		err  error
+	)
+
+	switch f.format {
+	case MaskFormat:
+		cset, err = ParseMask(value)
+	default:
+		cset, err = ParseList(value)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*f.cset = cset
+	return nil
+}
+
+// String implements the [flag.Value] interface.
+func (f *Flag) String() string {
+	// The flag package calls String on the zero value of the type when
+	// printing defaults.
+	if f.cset == nil {
+		return ""
+	}
+
+	switch f.format {
+	case MaskFormat:
+		return f.cset.MaskString()
+	default:
+		return f.cset.ListString()
+	}
+}
+
+// Get implements the [flag.Getter] interface. It returns the [CPUSet] of f.
+func (f *Flag) Get() any {
+	return *f.cset
+}
+
+// Type returns the name of the type of f, as expected by the
+// [github.com/spf13/pflag] package.
+//
+// [github.com/spf13/pflag]: https://pkg.go.dev/github.com/spf13/pflag#Value
+func (f *Flag) Type() string {
+	return "cpuset"
+}
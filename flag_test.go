@@ -0,0 +1,140 @@
+package cpuset
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCPUSetSet(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		args []string
+		want CPUSet
+		err  bool
+	}{
+		{
+			name: "default",
+			args: nil,
+			want: Of(0),
+		},
+		{
+			name: "invalid list string",
+			args: []string{"-cpus", "a"},
+			err:  true,
+		},
+		{
+			name: "list string",
+			args: []string{"-cpus", "0-3,8"},
+			want: Of(0, 1, 2, 3, 8),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+
+			cpus := Of(0)
+			fs.Var(&cpus, "cpus", "restrict to the specified CPUs")
+
+			switch err := fs.Parse(params.args); {
+			case err == nil && params.err:
+				t.Error("expected error")
+			case err != nil && !params.err:
+				t.Errorf("unexpected error: %v", err)
+			case err == nil && !cpus.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", cpus, params.want)
+			}
+		})
+	}
+}
+
+func TestFlagSet(t *testing.T) {
+	for _, params := range []struct {
+		name   string
+		format Format
+		value  string
+		want   CPUSet
+		err    error
+	}{
+		{
+			name:   "invalid list string",
+			format: ListFormat,
+			value:  "a",
+			err:    ErrInvalidElement,
+		},
+		{
+			name:   "invalid mask string",
+			format: MaskFormat,
+			value:  "0-3",
+			err:    ErrInvalidWord,
+		},
+		{
+			name:   "list string",
+			format: ListFormat,
+			value:  "0-3,8",
+			want:   Of(0, 1, 2, 3, 8),
+		},
+		{
+			name:   "mask string",
+			format: MaskFormat,
+			value:  "0000010f",
+			want:   Of(0, 1, 2, 3, 8),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			cpus := Of(0)
+			f := NewFlag(&cpus, params.format)
+
+			switch err := f.Set(params.value); {
+			case err == nil && params.err != nil:
+				t.Error("expected error")
+			case err != nil && !errors.Is(err, params.err):
+				t.Errorf("unexpected error: got %v, want %v", err, params.err)
+			case err != nil && !cpus.Equal(Of(0)):
+				t.Errorf("unexpected cpuset: got %v, want %v", cpus, Of(0))
+			case err == nil && !cpus.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", cpus, params.want)
+			case err == nil && f.String() != params.value:
+				t.Errorf("unexpected string: got %q, want %q", f.String(), params.value)
+			}
+		})
+	}
+}
+
+func TestFlagGet(t *testing.T) {
+	cpus := Of(0, 1)
+	if got, ok := NewFlag(&cpus, ListFormat).Get().(CPUSet); !ok || !got.Equal(cpus) {
+		t.Errorf("unexpected value: got %v, want %v", got, cpus)
+	}
+}
+
+func TestFlagPrintDefaults(t *testing.T) {
+	var b strings.Builder
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&b)
+
+	// Empty cpusets print no default, as flag compares String with the one
+	// of the zero value of the type, which is a Flag with no CPUSet.
+	var empty CPUSet
+	list, mask := Of(0, 1, 2, 3), Of(0, 32)
+	fs.Var(NewFlag(&empty, MaskFormat), "empty-mask", "restrict to the `cpus`")
+	fs.Var(&empty, "empty-list", "restrict to the `cpus`")
+	fs.Var(NewFlag(&mask, MaskFormat), "mask", "restrict to the `cpus`")
+	fs.Var(&list, "list", "restrict to the `cpus`")
+	fs.PrintDefaults()
+
+	want := `  -empty-list cpus
+    	restrict to the cpus
+  -empty-mask cpus
+    	restrict to the cpus
+  -list cpus
+    	restrict to the cpus (default 0-3)
+  -mask cpus
+    	restrict to the cpus (default 00000001,00000001)
+`
+	if got := b.String(); got != want {
+		t.Errorf("unexpected defaults: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package cpuset
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Format implements the [fmt.Formatter] interface. It supports the following
+// verbs, along with the width and flags applicable to strings:
+//
+//	%v, %s  list string, e.g. "0-3,8"
+//	%q      quoted list string, e.g. `"0-3,8"`
+//	%x, %X  mask string, e.g. "0000010f" or "0000010F"
+//	%d      comma-separated list of all the CPUs, e.g. "0,1,2,3,8"
+//	%+v     list string annotated with the number of CPUs, e.g. "0-3,8 (5 CPUs)"
+//	%#v     Go syntax, e.g. "cpuset.Of(0, 1, 2, 3, 8)"
+func (s CPUSet) Format(f fmt.State, verb rune) {
+	var str string
+
+	switch verb {
+	case 'v':
+		switch {
+		case f.Flag('#'):
+			str = "cpuset.Of(" + s.joinCPUs(", ") + ")"
+		case f.Flag('+'):
+			n, unit := s.Len(), "CPUs"
+			if n == 1 {
+				unit = "CPU"
+			}
+
+			str = strings.TrimLeft(fmt.Sprintf("%s (%d %s)", s.ListString(), n, unit), " ")
+		default:
+			str = s.ListString()
+		}
+
+		verb = 's'
+	case 's', 'q':
+		str = s.ListString()
+	case 'x':
+		str, verb = s.MaskString(), 's'
+	case 'X':
+		str, verb = strings.ToUpper(s.MaskString()), 's'
+	case 'd':
+		str, verb = s.joinCPUs(","), 's'
+	default:
+		fmt.Fprintf(f, "%%!%c(cpuset.CPUSet=%s)", verb, s.ListString())
+		return
+	}
+
+	fmt.Fprintf(f, fmt.FormatString(f, verb), str)
+}
+
+// LogValue implements the [slog.LogValuer] interface. It returns a group
+// holding the list string, the number of CPUs and the mask string of s.
+func (s CPUSet) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("list", s.ListString()),
+		slog.Int("count", s.Len()),
+		slog.String("mask", s.MaskString()),
+	)
+}
+
+// joinCPUs returns all the CPUs of s in ascending order, separated by sep.
+func (s *CPUSet) joinCPUs(sep string) string {
+	var b []byte
+	for cpu := range s.All() {
+		if len(b) > 0 {
+			b = append(b, sep...)
+		}
+
+		b = strconv.AppendUint(b, uint64(cpu), 10)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,122 @@
+package cpuset
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+func TestCPUSetFormat(t *testing.T) {
+	for _, params := range []struct {
+		name   string
+		format string
+		arg    any
+		want   string
+	}{
+		{
+			name:   "list (value)",
+			format: "%v",
+			arg:    Of(0, 1, 2, 3, 8),
+			want:   "0-3,8",
+		},
+		{
+			name:   "list (pointer)",
+			format: "%v",
+			arg:    &CPUSet{},
+			want:   "",
+		},
+		{
+			name:   "list (string)",
+			format: "%s",
+			arg:    Of(0, 1, 2, 3, 8),
+			want:   "0-3,8",
+		},
+		{
+			name:   "list (width)",
+			format: "[%-7s]",
+			arg:    Of(0, 1, 2, 3, 8),
+			want:   "[0-3,8  ]",
+		},
+		{
+			name:   "list (quoted)",
+			format: "%q",
+			arg:    Of(0, 1, 2, 3, 8),
+			want:   `"0-3,8"`,
+		},
+		{
+			name:   "list with count",
+			format: "%+v",
+			arg:    Of(0, 1, 2, 3, 8),
+			want:   "0-3,8 (5 CPUs)",
+		},
+		{
+			name:   "list with count of one",
+			format: "%+v",
+			arg:    Of(8),
+			want:   "8 (1 CPU)",
+		},
+		{
+			name:   "empty list with count",
+			format: "%+v",
+			arg:    CPUSet{},
+			want:   "(0 CPUs)",
+		},
+		{
+			name:   "Go syntax",
+			format: "%#v",
+			arg:    Of(0, 1, 2, 3, 8),
+			want:   "cpuset.Of(0, 1, 2, 3, 8)",
+		},
+		{
+			name:   "mask",
+			format: "%x",
+			arg:    Of(0, 1, 2, 3, 8, 32),
+			want:   "00000001,0000010f",
+		},
+		{
+			name:   "mask (upper case)",
+			format: "%X",
+			arg:    Of(0, 1, 2, 3, 8, 32),
+			want:   "00000001,0000010F",
+		},
+		{
+			name:   "expanded list",
+			format: "%d",
+			arg:    Of(0, 1, 2, 3, 8),
+			want:   "0,1,2,3,8",
+		},
+		{
+			name:   "bad verb",
+			format: "%t",
+			arg:    Of(0, 1, 2, 3, 8),
+			want:   "%!t(cpuset.CPUSet=0-3,8)",
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := fmt.Sprintf(params.format, params.arg); got != params.want {
+				t.Errorf("unexpected string: got %q, want %q", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetLogValue(t *testing.T) {
+	var b bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	}))
+
+	logger.Info("pinned", "cpus", Of(0, 1, 2, 3, 8))
+
+	want := "level=INFO msg=pinned cpus.list=0-3,8 cpus.count=5 cpus.mask=0000010f\n"
+	if got := b.String(); got != want {
+		t.Errorf("unexpected log: got %q, want %q", got, want)
+	}
+}
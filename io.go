@@ -0,0 +1,188 @@
+package cpuset
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+)
+
+// ReadList decodes the list string read from r until EOF into a [CPUSet],
+// ignoring white space such as a trailing newline (see
+// [ParseOptions.TrimSpace]).
+func ReadList(r io.Reader) (CPUSet, error) {
+	return ParseOptions{}.ReadList(r)
+}
+
+// ReadList is like [ReadList] but uses the options of o.
+func (o ParseOptions) ReadList(r io.Reader) (CPUSet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return CPUSet{}, err
+	}
+
+	o.TrimSpace = true
+	return o.ParseList(string(data))
+}
+
+// ReadMask decodes the mask string read from r until EOF into a [CPUSet],
+// ignoring white space such as a trailing newline (see
+// [ParseOptions.TrimSpace]).
+func ReadMask(r io.Reader) (CPUSet, error) {
+	return ParseOptions{}.ReadMask(r)
+}
+
+// ReadMask is like [ReadMask] but uses the options of o.
+func (o ParseOptions) ReadMask(r io.Reader) (CPUSet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return CPUSet{}, err
+	}
+
+	o.TrimSpace = true
+	return o.ParseMask(string(data))
+}
+
+// ParseListFile decodes the list string of the named file, such as
+// "/sys/fs/cgroup/cpuset.cpus.effective", into a [CPUSet]. See [ReadList].
+func ParseListFile(name string) (CPUSet, error) {
+	return ParseOptions{}.ParseListFile(name)
+}
+
+// ParseListFile is like [ParseListFile] but uses the options of o.
+func (o ParseOptions) ParseListFile(name string) (CPUSet, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return CPUSet{}, err
+	}
+	defer f.Close()
+
+	return o.ReadList(f)
+}
+
+// ParseMaskFile decodes the mask string of the named file, such as
+// "/proc/irq/0/smp_affinity", into a [CPUSet]. See [ReadMask].
+func ParseMaskFile(name string) (CPUSet, error) {
+	return ParseOptions{}.ParseMaskFile(name)
+}
+
+// ParseMaskFile is like [ParseMaskFile] but uses the options of o.
+func (o ParseOptions) ParseMaskFile(name string) (CPUSet, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return CPUSet{}, err
+	}
+	defer f.Close()
+
+	return o.ReadMask(f)
+}
+
+// WriteList writes the list string encoding of s to w. It returns the number
+// of bytes written and any write error encountered.
+func (s *CPUSet) WriteList(w io.Writer) (int, error) {
+	var buf [64]byte
+	return w.Write(s.AppendList(buf[:0]))
+}
+
+// A Decoder reads and decodes cpuset strings from an input stream, one per
+// line, such as the concatenated contents of several kernel files.
+type Decoder struct {
+	r      *bufio.Reader
+	format Format
+	opts   ParseOptions
+	line   []byte
+}
+
+// NewDecoder returns a new [Decoder] reading cpuset strings encoded in
+// format from r.
+func NewDecoder(r io.Reader, format Format) *Decoder {
+	return ParseOptions{}.NewDecoder(r, format)
+}
+
+// NewDecoder is like [NewDecoder] but decodes using the options of o.
+func (o ParseOptions) NewDecoder(r io.Reader, format Format) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), format: format, opts: o}
+}
+
+// Decode reads the next line of its input, without its line ending, and
+// decodes it into the [CPUSet] pointed to by p. It returns [io.EOF] when
+// there is no more input, or the error of the reader if reading fails. A
+// line that cannot be decoded yields a [*ParseError] and is skipped, so that
+// decoding can go on.
+func (d *Decoder) Decode(p *CPUSet) error {
+	line, err := d.readLine()
+	if err != nil {
+		return err
+	}
+
+	var cset CPUSet
+	switch d.format {
+	case MaskFormat:
+		cset, err = d.opts.ParseMask(string(line))
+	default:
+		cset, err = d.opts.ParseList(string(line))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*p = cset
+	return nil
+}
+
+// readLine reads the next line of the input, growing the line buffer of d
+// as long as the line does not fit the buffer of the reader.
+func (d *Decoder) readLine() ([]byte, error) {
+	d.line = d.line[:0]
+	for {
+		chunk, err := d.r.ReadSlice('\n')
+		d.line = append(d.line, chunk...)
+
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err == io.EOF && len(d.line) > 0:
+			// The last line has no line ending.
+		case err != nil:
+			return nil, err
+		}
+
+		line, _ := bytes.CutSuffix(d.line, []byte{'\n'})
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+
+		return line, nil
+	}
+}
+
+// An Encoder encodes and writes cpuset strings to an output stream, one per
+// line.
+type Encoder struct {
+	w      io.Writer
+	format Format
+	buf    []byte
+}
+
+// NewEncoder returns a new [Encoder] writing cpuset strings encoded in format
+// to w.
+func NewEncoder(w io.Writer, format Format) *Encoder {
+	return &Encoder{w: w, format: format}
+}
+
+// Encode writes the encoding of s to the output stream, followed by a
+// newline character.
+func (e *Encoder) Encode(s CPUSet) error {
+	e.buf = e.buf[:0]
+	switch e.format {
+	case MaskFormat:
+		e.buf = s.AppendMask(e.buf)
+	default:
+		e.buf = s.AppendList(e.buf)
+	}
+
+	e.buf = append(e.buf, '\n')
+	_, err := e.w.Write(e.buf)
+	return err
+}
@@ -0,0 +1,330 @@
+package cpuset
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadList(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		data string
+		want CPUSet
+		err  bool
+	}{
+		{
+			name: "trailing newline",
+			data: "0-3,8\n",
+			want: Of(0, 1, 2, 3, 8),
+		},
+		{
+			name: "empty line",
+			data: "\n",
+			want: CPUSet{},
+		},
+		{
+			name: "invalid list",
+			data: "0-x\n",
+			err:  true,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			switch got, err := ReadList(strings.NewReader(params.data)); {
+			case err == nil && params.err:
+				t.Error("expected error")
+			case err != nil && !params.err:
+				t.Errorf("unexpected error: %v", err)
+			case err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestReadMask(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		data string
+		want CPUSet
+		err  bool
+	}{
+		{
+			name: "trailing newline",
+			data: "00000000,0000010f\n",
+			want: Of(0, 1, 2, 3, 8),
+		},
+		{
+			name: "empty line",
+			data: "\n",
+			want: CPUSet{},
+		},
+		{
+			name: "invalid mask",
+			data: "0000000x\n",
+			err:  true,
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			switch got, err := ReadMask(strings.NewReader(params.data)); {
+			case err == nil && params.err:
+				t.Error("expected error")
+			case err != nil && !params.err:
+				t.Errorf("unexpected error: %v", err)
+			case err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestReadError(t *testing.T) {
+	errRead := errors.New("read error")
+
+	if _, err := ReadList(iotest.ErrReader(errRead)); !errors.Is(err, errRead) {
+		t.Errorf("unexpected list error: got %v, want %v", err, errRead)
+	}
+
+	if _, err := ReadMask(iotest.ErrReader(errRead)); !errors.Is(err, errRead) {
+		t.Errorf("unexpected mask error: got %v, want %v", err, errRead)
+	}
+}
+
+func TestParseOptionsReadList(t *testing.T) {
+	var rangeErr *RangeError
+	if _, err := (ParseOptions{NumCPUs: 4}).ReadList(strings.NewReader("0-4\n")); !errors.As(err, &rangeErr) {
+		t.Errorf("unexpected error: got %v, want a RangeError", err)
+	}
+}
+
+func TestParseListFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "cpuset.cpus.effective")
+	if err := os.WriteFile(name, []byte("0-3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	switch got, err := ParseListFile(name); {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !got.Equal(rangeOf(0, 3)):
+		t.Errorf("unexpected cpuset: got %v, want %v", got, rangeOf(0, 3))
+	}
+
+	if _, err := ParseListFile(name + ".missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("unexpected error: got %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestParseMaskFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "smp_affinity")
+	if err := os.WriteFile(name, []byte("00000000,0000000f\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	switch got, err := ParseMaskFile(name); {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !got.Equal(rangeOf(0, 3)):
+		t.Errorf("unexpected cpuset: got %v, want %v", got, rangeOf(0, 3))
+	}
+
+	if _, err := ParseMaskFile(name + ".missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("unexpected error: got %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestCPUSetWriteList(t *testing.T) {
+	var b strings.Builder
+	s := Of(0, 1, 2, 7, 12, 13, 14)
+
+	switch n, err := s.WriteList(&b); {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case b.String() != "0-2,7,12-14":
+		t.Errorf("unexpected list string: got %q, want %q", b.String(), "0-2,7,12-14")
+	case n != b.Len():
+		t.Errorf("unexpected byte count: got %d, want %d", n, b.Len())
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	for _, params := range []struct {
+		name   string
+		opts   ParseOptions
+		format Format
+		data   string
+		want   []CPUSet
+		errs   []bool
+	}{
+		{
+			name:   "list strings",
+			format: ListFormat,
+			data:   "0-3\n\n8,9\r\n16",
+			want:   []CPUSet{rangeOf(0, 3), {}, Of(8, 9), Of(16)},
+			errs:   []bool{false, false, false, false},
+		},
+		{
+			name:   "CRLF line endings",
+			format: ListFormat,
+			data:   "0-3\r\n5\r",
+			want:   []CPUSet{rangeOf(0, 3), Of(5)},
+			errs:   []bool{false, false},
+		},
+		{
+			name:   "mask strings",
+			format: MaskFormat,
+			data:   "0000000f\n00000001,00000000\n",
+			want:   []CPUSet{rangeOf(0, 3), Of(32)},
+			errs:   []bool{false, false},
+		},
+		{
+			name:   "invalid line skipped",
+			format: ListFormat,
+			data:   "0-3\nx\n4\n",
+			want:   []CPUSet{rangeOf(0, 3), {}, Of(4)},
+			errs:   []bool{false, true, false},
+		},
+		{
+			name:   "line longer than the read buffer",
+			format: ListFormat,
+			data:   strings.Repeat("0,", 4096) + "1\n",
+			want:   []CPUSet{Of(0, 1)},
+			errs:   []bool{false},
+		},
+		{
+			name:   "options",
+			opts:   ParseOptions{TrimSpace: true, Mask: MaskOptions{Width: 64}},
+			format: MaskFormat,
+			data:   " 00000000,00000003 \n",
+			want:   []CPUSet{Of(0, 1)},
+			errs:   []bool{false},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			dec := params.opts.NewDecoder(strings.NewReader(params.data), params.format)
+			for i, want := range params.want {
+				var got CPUSet
+				switch err := dec.Decode(&got); {
+				case err == nil && params.errs[i]:
+					t.Errorf("line %d: expected error", i)
+				case err != nil && !params.errs[i]:
+					t.Errorf("line %d: unexpected error: %v", i, err)
+				case err == nil && !got.Equal(want):
+					t.Errorf("line %d: unexpected cpuset: got %v, want %v", i, got, want)
+				}
+			}
+
+			if err := dec.Decode(new(CPUSet)); err != io.EOF {
+				t.Errorf("unexpected error: got %v, want %v", err, io.EOF)
+			}
+		})
+	}
+}
+
+func TestDecoderParseError(t *testing.T) {
+	for _, opts := range []ParseOptions{{}, {Mask: MaskOptions{WordBitSize: 16}}} {
+		var perr *ParseError
+		dec := opts.NewDecoder(strings.NewReader("0000000x\n"), MaskFormat)
+		if err := dec.Decode(new(CPUSet)); !errors.As(err, &perr) {
+			t.Errorf("unexpected error: got %v, want a ParseError", err)
+		}
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	for _, params := range []struct {
+		name   string
+		format Format
+		sets   []CPUSet
+		want   string
+	}{
+		{
+			name:   "list strings",
+			format: ListFormat,
+			sets:   []CPUSet{rangeOf(0, 3), {}, Of(8, 9)},
+			want:   "0-3\n\n8-9\n",
+		},
+		{
+			name:   "mask strings",
+			format: MaskFormat,
+			sets:   []CPUSet{rangeOf(0, 3), Of(32)},
+			want:   "0000000f\n00000001,00000000\n",
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			var b strings.Builder
+			enc := NewEncoder(&b, params.format)
+			for _, s := range params.sets {
+				if err := enc.Encode(s); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if got := b.String(); got != params.want {
+				t.Errorf("unexpected output: got %q, want %q", got, params.want)
+			}
+		})
+	}
+}
+
+func TestEncoderRoundTrip(t *testing.T) {
+	s1, s2 := benchmarkCPUSets()
+
+	var b strings.Builder
+	enc := NewEncoder(&b, MaskFormat)
+	for _, s := range []CPUSet{s1, s2} {
+		if err := enc.Encode(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	dec := NewDecoder(strings.NewReader(b.String()), MaskFormat)
+	for _, want := range []CPUSet{s1, s2} {
+		var got CPUSet
+		switch err := dec.Decode(&got); {
+		case err != nil:
+			t.Errorf("unexpected error: %v", err)
+		case !got.Equal(want):
+			t.Errorf("unexpected cpuset: got %v, want %v", got, want)
+		}
+	}
+}
+
+func BenchmarkCPUSetWriteList(b *testing.B) {
+	s, _ := benchmarkCPUSets()
+	b.ReportAllocs()
+	for range b.N {
+		s.WriteList(io.Discard)
+	}
+}
+
+func BenchmarkEncoder(b *testing.B) {
+	s, _ := benchmarkCPUSets()
+	enc := NewEncoder(io.Discard, ListFormat)
+	b.ReportAllocs()
+	for range b.N {
+		enc.Encode(s)
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	s, _ := benchmarkCPUSets()
+	data := strings.Repeat(s.ListString()+"\n", 64)
+	r := strings.NewReader(data)
+	dec := NewDecoder(r, ListFormat)
+	b.ReportAllocs()
+	for range b.N {
+		var cset CPUSet
+		if err := dec.Decode(&cset); err == io.EOF {
+			b.StopTimer()
+			r.Reset(data)
+			dec = NewDecoder(r, ListFormat)
+			b.StartTimer()
+		}
+	}
+}
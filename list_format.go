@@ -3,7 +3,6 @@ package cpuset
 import (
 	"fmt"
 	"math/bits"
-	"slices"
 	"strconv"
 	"strings"
 )
@@ -17,92 +16,209 @@ const (
 // list string, as specified in the [Linux cpuset(7) man page] (see "List
 // Format").
 //
+// CPUs must be lower than [DefaultLimit], use [ParseOptions.ParseList] to
+// change it.
+//
 // [Linux cpuset(7) man page]: https://man7.org/linux/man-pages/man7/cpuset.7.html
-func ParseList(s string) (cset CPUSet, _ error) {
-	if s == "" {
+func ParseList(s string) (CPUSet, error) {
+	return ParseOptions{}.ParseList(s)
+}
+
+// ParseList is like [ParseList] but uses the options of o.
+func (o ParseOptions) ParseList(s string) (cset CPUSet, _ error) {
+	input := s
+	s, offset := o.trimSpace(s)
+	if s == "" || s == "none" && o.NumCPUs > 0 {
 		return CPUSet{}, nil
 	}
 
-	for _, elem := range strings.Split(s, ",") {
-		switch parts := strings.Split(elem, "-"); len(parts) {
-		case 1:
+	limit := o.limit()
+	for rest, more := s, true; more; {
+		var rawElem string
+		rawElem, rest, more = strings.Cut(rest, ",")
+
+		elem, leading := o.trimSpace(rawElem)
+		perr := ParseError{Input: input, Format: ListFormat, Offset: offset + leading, Element: elem}
+		offset += len(rawElem) + 1
+
+		rangeStr, pattern, hasPattern := strings.Cut(elem, ":")
+
+		parts := strings.Split(rangeStr, "-")
+		if rangeStr == "all" && o.NumCPUs > 0 {
+			parts = []string{"0", "N"}
+		}
+
+		switch {
+		case len(parts) == 1 && !hasPattern:
 			part, exclude := strings.CutPrefix(parts[0], "^")
 
-			ui64, err := strconv.ParseUint(part, partBase, partBitSize)
+			cpu, err := o.parseUint(part)
 			if err != nil {
-				return CPUSet{}, formatParseError(s, fmt.Sprintf("invalid element %q", elem))
+				return CPUSet{}, perr.with(ErrInvalidElement, elem, nil)
+			}
+			if cpu >= limit {
+				return CPUSet{}, perr.with(ErrOutOfRange, elem, &RangeError{CPU: cpu, Limit: limit})
 			}
 
-			if cpu := uint(ui64); exclude {
+			if exclude {
 				cset.Delete(cpu)
 			} else {
 				cset.Add(cpu)
 			}
 
-		case 2:
-			ui64, err := strconv.ParseUint(parts[0], partBase, partBitSize)
+		case len(parts) == 2:
+			lowerBound, err := o.parseUint(parts[0])
 			if err != nil {
-				return CPUSet{}, formatParseError(s, fmt.Sprintf("invalid lower bound %q in range %q", parts[0], elem))
+				return CPUSet{}, perr.with(ErrInvalidLowerBound, parts[0], nil)
 			}
 
-			lowerBound := uint(ui64)
-
-			ui64, err = strconv.ParseUint(parts[1], partBase, partBitSize)
+			upperBound, err := o.parseUint(parts[1])
 			if err != nil {
-				return CPUSet{}, formatParseError(s, fmt.Sprintf("invalid upper bound %q in range %q", parts[1], elem))
+				return CPUSet{}, perr.with(ErrInvalidUpperBound, parts[1], nil)
 			}
 
-			upperBound := uint(ui64)
-
 			if upperBound < lowerBound {
-				return CPUSet{}, formatParseError(s, fmt.Sprintf("negative range %q", elem))
+				return CPUSet{}, perr.with(ErrNegativeRange, elem, nil)
+			}
+
+			if upperBound >= limit {
+				return CPUSet{}, perr.with(ErrOutOfRange, parts[1], &RangeError{CPU: upperBound, Limit: limit})
+			}
+
+			if !hasPattern {
+				cset.AddRange(lowerBound, upperBound)
+				break
+			}
+
+			usedStr, groupStr, ok := strings.Cut(pattern, "/")
+			if !ok {
+				return CPUSet{}, perr.with(ErrInvalidPattern, pattern, nil)
+			}
+
+			used, err := o.parseUint(usedStr)
+			if err != nil {
+				return CPUSet{}, perr.with(ErrInvalidUsedSize, usedStr, nil)
+			}
+
+			group, err := o.parseUint(groupStr)
+			if err != nil {
+				return CPUSet{}, perr.with(ErrInvalidGroupSize, groupStr, nil)
 			}
 
-			for i := range upperBound - lowerBound + 1 {
-				cset.Add(lowerBound + i)
+			if group == 0 || used > group {
+				return CPUSet{}, perr.with(ErrInvalidPattern, pattern, nil)
 			}
 
+			cset.addStride(lowerBound, upperBound, used, group)
+
 		default:
-			return CPUSet{}, formatParseError(s, fmt.Sprintf("invalid element %q", elem))
+			return CPUSet{}, perr.with(ErrInvalidElement, elem, nil)
 		}
 	}
 
 	return cset, nil
 }
 
+// parseUint decodes a number of a list string, resolving the "N" keyword if
+// enabled.
+func (o ParseOptions) parseUint(s string) (uint, error) {
+	if s == "N" && o.NumCPUs > 0 {
+		return o.NumCPUs - 1, nil
+	}
+
+	ui64, err := strconv.ParseUint(s, partBase, partBitSize)
+	return uint(ui64), err
+}
+
 // ListString encodes s into a list string.
 func (s *CPUSet) ListString() string {
-	if s.Len() == 0 {
-		return ""
+	var buf [64]byte
+	return string(s.AppendList(buf[:0]))
+}
+
+// AppendList appends the list string encoding of s to b and returns the
+// extended buffer.
+func (s *CPUSet) AppendList(b []byte) []byte {
+	sep := false
+	for lowerBound, upperBound := range s.Ranges() {
+		if sep {
+			b = append(b, ',')
+		}
+
+		b = appendListElem(b, lowerBound, upperBound)
+		sep = true
 	}
 
-	cpus := s.UnsortedList()
-	slices.Sort(cpus)
+	return b
+}
 
-	var (
-		elems      []string
-		lowerBound = cpus[0]
-		upperBound = cpus[0]
-	)
+// CompactListString encodes s into a list string, folding runs of CPUs
+// repeating at a regular interval into the "lower-upper:used/group" stride
+// notation understood by the Linux kernel (see the [kernel parameters
+// documentation]) whenever the result is shorter.
+//
+// [kernel parameters documentation]: https://docs.kernel.org/admin-guide/kernel-parameters.html#cpu-lists
+func (s *CPUSet) CompactListString() string {
+	var runs [][2]uint
+	for lowerBound, upperBound := range s.Ranges() {
+		runs = append(runs, [2]uint{lowerBound, upperBound})
+	}
 
-	for _, cpu := range cpus[1:] {
-		if cpu != upperBound+1 {
-			elems = append(elems, formatListElem(lowerBound, upperBound))
-			lowerBound = cpu
+	var elems []string
+	for i := 0; i < len(runs); {
+		// Extend the stride starting at runs[i] as far as runs keep the
+		// same size and the same distance to each other. The last run of a
+		// stride may be shorter, as the upper bound truncates it.
+		j := i + 1
+		if j < len(runs) {
+			used := runs[i][1] - runs[i][0] + 1
+			group := runs[j][0] - runs[i][0]
+			for j < len(runs) &&
+				runs[j][0]-runs[j-1][0] == group &&
+				runs[j-1][1]-runs[j-1][0]+1 == used &&
+				runs[j][1]-runs[j][0]+1 <= used {
+				j++
+			}
+
+			stride := formatStrideElem(runs[i][0], runs[j-1][1], used, group)
+			if plain := formatListElems(runs[i:j]); len(stride) < len(plain) {
+				elems = append(elems, stride)
+				i = j
+				continue
+			}
 		}
 
-		upperBound = cpu
+		elems = append(elems, formatListElem(runs[i][0], runs[i][1]))
+		i++
 	}
 
-	elems = append(elems, formatListElem(lowerBound, upperBound))
+	return strings.Join(elems, ",")
+}
+
+func formatListElems(runs [][2]uint) string {
+	elems := make([]string, len(runs))
+	for i, run := range runs {
+		elems[i] = formatListElem(run[0], run[1])
+	}
 
 	return strings.Join(elems, ",")
 }
 
+func formatStrideElem(lowerBound, upperBound, used, group uint) string {
+	return fmt.Sprint(lowerBound, "-", upperBound, ":", used, "/", group)
+}
+
 func formatListElem(lowerBound, upperBound uint) string {
+	var buf [32]byte
+	return string(appendListElem(buf[:0], lowerBound, upperBound))
+}
+
+func appendListElem(b []byte, lowerBound, upperBound uint) []byte {
+	b = strconv.AppendUint(b, uint64(lowerBound), partBase)
 	if lowerBound == upperBound {
-		return fmt.Sprint(lowerBound)
+		return b
 	}
 
-	return fmt.Sprint(lowerBound, "-", upperBound)
+	b = append(b, '-')
+	return strconv.AppendUint(b, uint64(upperBound), partBase)
 }
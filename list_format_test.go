@@ -1,6 +1,7 @@
 package cpuset
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -9,67 +10,112 @@ func TestParseList(t *testing.T) {
 		name string
 		s    string
 		want CPUSet
-		err  error
+		err  *ParseError
 	}{
 		{
 			name: `invalid element ""`,
 			s:    ",",
-			err:  formatParseError(",", `invalid element ""`),
+			err:  &ParseError{Input: ",", Format: ListFormat, Element: "", Part: "", Kind: ErrInvalidElement},
 		},
 		{
 			name: `invalid element "^"`,
 			s:    "^",
-			err:  formatParseError("^", `invalid element "^"`),
+			err:  &ParseError{Input: "^", Format: ListFormat, Element: "^", Part: "^", Kind: ErrInvalidElement},
 		},
 		{
 			name: `invalid element "a"`,
 			s:    "a",
-			err:  formatParseError("a", `invalid element "a"`),
+			err:  &ParseError{Input: "a", Format: ListFormat, Element: "a", Part: "a", Kind: ErrInvalidElement},
 		},
 		{
 			name: `invalid element "0a"`,
 			s:    "0a",
-			err:  formatParseError("0a", `invalid element "0a"`),
+			err:  &ParseError{Input: "0a", Format: ListFormat, Element: "0a", Part: "0a", Kind: ErrInvalidElement},
 		},
 		{
 			name: `invalid lower bound "" in range "-1"`,
 			s:    "-1",
-			err:  formatParseError("-1", `invalid lower bound "" in range "-1"`),
+			err:  &ParseError{Input: "-1", Format: ListFormat, Element: "-1", Part: "", Kind: ErrInvalidLowerBound},
 		},
 		{
 			name: `invalid lower bound "a" in range "a-1"`,
 			s:    "a-1",
-			err:  formatParseError("a-1", `invalid lower bound "a" in range "a-1"`),
+			err:  &ParseError{Input: "a-1", Format: ListFormat, Element: "a-1", Part: "a", Kind: ErrInvalidLowerBound},
 		},
 		{
 			name: `invalid lower bound "0a" in range "0a-1"`,
 			s:    "0a-1",
-			err:  formatParseError("0a-1", `invalid lower bound "0a" in range "0a-1"`),
+			err:  &ParseError{Input: "0a-1", Format: ListFormat, Element: "0a-1", Part: "0a", Kind: ErrInvalidLowerBound},
 		},
 		{
 			name: `invalid upper bound "" in range "0-"`,
 			s:    "0-",
-			err:  formatParseError("0-", `invalid upper bound "" in range "0-"`),
+			err:  &ParseError{Input: "0-", Format: ListFormat, Element: "0-", Part: "", Kind: ErrInvalidUpperBound},
 		},
 		{
 			name: `invalid upper bound "b" in range "0-b"`,
 			s:    "0-b",
-			err:  formatParseError("0-b", `invalid upper bound "b" in range "0-b"`),
+			err:  &ParseError{Input: "0-b", Format: ListFormat, Element: "0-b", Part: "b", Kind: ErrInvalidUpperBound},
 		},
 		{
 			name: `invalid upper bound "1b" in range "0-1b"`,
 			s:    "0-1b",
-			err:  formatParseError("0-1b", `invalid upper bound "1b" in range "0-1b"`),
+			err:  &ParseError{Input: "0-1b", Format: ListFormat, Element: "0-1b", Part: "1b", Kind: ErrInvalidUpperBound},
 		},
 		{
 			name: `negative range "1-0"`,
 			s:    "1-0",
-			err:  formatParseError("1-0", `negative range "1-0"`),
+			err:  &ParseError{Input: "1-0", Format: ListFormat, Element: "1-0", Part: "1-0", Kind: ErrNegativeRange},
+		},
+		{
+			name: `invalid element "a" at offset 4`,
+			s:    "0-3,a,5",
+			err:  &ParseError{Input: "0-3,a,5", Format: ListFormat, Offset: 4, Element: "a", Part: "a", Kind: ErrInvalidElement},
 		},
 		{
 			name: `invalid element "0-1-2"`,
 			s:    "0-1-2",
-			err:  formatParseError("0-1-2", `invalid element "0-1-2"`),
+			err:  &ParseError{Input: "0-1-2", Format: ListFormat, Element: "0-1-2", Part: "0-1-2", Kind: ErrInvalidElement},
+		},
+		{
+			name: `invalid element "1:1/2"`,
+			s:    "1:1/2",
+			err:  &ParseError{Input: "1:1/2", Format: ListFormat, Element: "1:1/2", Part: "1:1/2", Kind: ErrInvalidElement},
+		},
+		{
+			name: `invalid pattern "3" in range "0-9:3"`,
+			s:    "0-9:3",
+			err:  &ParseError{Input: "0-9:3", Format: ListFormat, Element: "0-9:3", Part: "3", Kind: ErrInvalidPattern},
+		},
+		{
+			name: `invalid used size "a" in range "0-9:a/4"`,
+			s:    "0-9:a/4",
+			err:  &ParseError{Input: "0-9:a/4", Format: ListFormat, Element: "0-9:a/4", Part: "a", Kind: ErrInvalidUsedSize},
+		},
+		{
+			name: `invalid group size "b" in range "0-9:3/b"`,
+			s:    "0-9:3/b",
+			err:  &ParseError{Input: "0-9:3/b", Format: ListFormat, Element: "0-9:3/b", Part: "b", Kind: ErrInvalidGroupSize},
+		},
+		{
+			name: `invalid pattern "5/4" in range "0-9:5/4"`,
+			s:    "0-9:5/4",
+			err:  &ParseError{Input: "0-9:5/4", Format: ListFormat, Element: "0-9:5/4", Part: "5/4", Kind: ErrInvalidPattern},
+		},
+		{
+			name: `invalid pattern "0/0" in range "0-9:0/0"`,
+			s:    "0-9:0/0",
+			err:  &ParseError{Input: "0-9:0/0", Format: ListFormat, Element: "0-9:0/0", Part: "0/0", Kind: ErrInvalidPattern},
+		},
+		{
+			name: `CPU 8192 out of range in element "8192"`,
+			s:    "8192",
+			err:  &ParseError{Input: "8192", Format: ListFormat, Element: "8192", Part: "8192", Kind: ErrOutOfRange, Err: &RangeError{CPU: 8192, Limit: DefaultLimit}},
+		},
+		{
+			name: `CPU 8192 out of range in range "0-8192"`,
+			s:    "0-8192",
+			err:  &ParseError{Input: "0-8192", Format: ListFormat, Element: "0-8192", Part: "8192", Kind: ErrOutOfRange, Err: &RangeError{CPU: 8192, Limit: DefaultLimit}},
 		},
 		{
 			name: "no bit set",
@@ -91,6 +137,21 @@ func TestParseList(t *testing.T) {
 			s:    "1-4,^3,6",
 			want: Of(1, 2, 4, 6),
 		},
+		{
+			name: "bits 0, 1, 256, 257, 512, 513, 768, and 769 set",
+			s:    "0-1023:2/256",
+			want: Of(0, 1, 256, 257, 512, 513, 768, 769),
+		},
+		{
+			name: "bits 0, 1, 2, 4, 5, 6, 8, and 9 set",
+			s:    "0-9:3/4",
+			want: Of(0, 1, 2, 4, 5, 6, 8, 9),
+		},
+		{
+			name: "no bit set with pattern",
+			s:    "0-9:0/4",
+			want: CPUSet{},
+		},
 	} {
 		t.Run(params.name, func(t *testing.T) {
 			switch got, err := ParseList(params.s); {
@@ -98,8 +159,129 @@ func TestParseList(t *testing.T) {
 				t.Error("expected error")
 			case err != nil && params.err == nil:
 				t.Errorf("unexpected error: %v", err)
-			case err != nil && params.err != nil && err.Error() != params.err.Error():
-				t.Errorf("unexpected error: got %v, want %v", err, params.err)
+			case err != nil && params.err != nil && !equalParseError(err, params.err):
+				t.Errorf("unexpected error: got %#v, want %#v", err, params.err)
+			case err == nil && params.err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestParseOptionsParseList(t *testing.T) {
+	for _, params := range []struct {
+		name  string
+		limit uint
+		s     string
+		want  CPUSet
+		err   *RangeError
+	}{
+		{
+			name:  "CPU 4 out of range [0, 4)",
+			limit: 4,
+			s:     "0-2,4",
+			err:   &RangeError{CPU: 4, Limit: 4},
+		},
+		{
+			name:  "CPU 18446744073709551615 out of range [0, 4)",
+			limit: 4,
+			s:     "0-18446744073709551615",
+			err:   &RangeError{CPU: 18446744073709551615, Limit: 4},
+		},
+		{
+			name:  "bits 0, 1, 2, and 3 set",
+			limit: 4,
+			s:     "0-3",
+			want:  Of(0, 1, 2, 3),
+		},
+		{
+			name:  "bit 10000 set",
+			limit: 10001,
+			s:     "10000",
+			want:  Of(10000),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			var rangeErr *RangeError
+			switch got, err := (ParseOptions{Limit: params.limit}).ParseList(params.s); {
+			case err == nil && params.err != nil:
+				t.Error("expected error")
+			case err != nil && params.err == nil:
+				t.Errorf("unexpected error: %v", err)
+			case err != nil && !errors.As(err, &rangeErr):
+				t.Errorf("unexpected error type: %T", err)
+			case err != nil && *rangeErr != *params.err:
+				t.Errorf("unexpected error: got %v, want %v", rangeErr, params.err)
+			case err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestParseOptionsParseListNumCPUs(t *testing.T) {
+	for _, params := range []struct {
+		name    string
+		numCPUs uint
+		s       string
+		want    CPUSet
+		err     *ParseError
+	}{
+		{
+			name: `invalid element "N" without NumCPUs`,
+			s:    "1-N",
+			err:  &ParseError{Input: "1-N", Format: ListFormat, Element: "1-N", Part: "N", Kind: ErrInvalidUpperBound},
+		},
+		{
+			name: `invalid element "all" without NumCPUs`,
+			s:    "all",
+			err:  &ParseError{Input: "all", Format: ListFormat, Element: "all", Part: "all", Kind: ErrInvalidElement},
+		},
+		{
+			name:    `invalid element "none,1"`,
+			numCPUs: 4,
+			s:       "none,1",
+			err:     &ParseError{Input: "none,1", Format: ListFormat, Element: "none", Part: "none", Kind: ErrInvalidElement},
+		},
+		{
+			name:    "CPU 4 out of range [0, 4)",
+			numCPUs: 4,
+			s:       "4",
+			err:     &ParseError{Input: "4", Format: ListFormat, Element: "4", Part: "4", Kind: ErrOutOfRange, Err: &RangeError{CPU: 4, Limit: 4}},
+		},
+		{
+			name:    "bits 1, 2, and 3 set",
+			numCPUs: 4,
+			s:       "1-N",
+			want:    Of(1, 2, 3),
+		},
+		{
+			name:    "bits 0, 1, and 2 set",
+			numCPUs: 4,
+			s:       "all,^N",
+			want:    Of(0, 1, 2),
+		},
+		{
+			name:    "bits 0, 2, 4, and 6 set",
+			numCPUs: 8,
+			s:       "all:1/2",
+			want:    Of(0, 2, 4, 6),
+		},
+		{
+			name:    "no bit set",
+			numCPUs: 4,
+			s:       "none",
+			want:    CPUSet{},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			switch got, err := (ParseOptions{NumCPUs: params.numCPUs}).ParseList(params.s); {
+			case err == nil && params.err != nil:
+				t.Error("expected error")
+			case err != nil && params.err == nil:
+				t.Errorf("unexpected error: %v", err)
+			case err != nil && params.err != nil && !equalParseError(err, params.err):
+				t.Errorf("unexpected error: got %#v, want %#v", err, params.err)
 			case err == nil && params.err == nil && !got.Equal(params.want):
 				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
 			}
@@ -136,3 +318,144 @@ func TestListString(t *testing.T) {
 		})
 	}
 }
+
+func TestCPUSetAppendList(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		b    []byte
+		s    CPUSet
+		want string
+	}{
+		{
+			name: "no bit set",
+			b:    []byte("cpus="),
+			s:    CPUSet{},
+			want: "cpus=",
+		},
+		{
+			name: "bits 0, 1, 2, 7, 12, 13, and 14 set",
+			b:    []byte("cpus="),
+			s:    Of(0, 1, 2, 7, 12, 13, 14),
+			want: "cpus=0-2,7,12-14",
+		},
+		{
+			name: "nil buffer",
+			s:    Of(1, 8191),
+			want: "1,8191",
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.s.AppendList(params.b); string(got) != params.want {
+				t.Errorf("unexpected list string: got %q, want %q", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCompactListString(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			name: "no bit set",
+			s:    "",
+			want: "",
+		},
+		{
+			name: "no stride",
+			s:    "0-4,9",
+			want: "0-4,9",
+		},
+		{
+			name: "stride shorter",
+			s:    "0-1,256-257,512-513,768-769",
+			want: "0-769:2/256",
+		},
+		{
+			name: "stride longer",
+			s:    "0,2",
+			want: "0,2",
+		},
+		{
+			name: "stride with truncated last run",
+			s:    "0-2,4-6,8-10,12-13",
+			want: "0-13:3/4",
+		},
+		{
+			name: "stride followed by longer run",
+			s:    "0,10,20,30,40,50-59",
+			want: "0-40:1/10,50-59",
+		},
+		{
+			name: "two strides",
+			s:    "0,10,20,30,100-101,200-201,300-301,400-401",
+			want: "0-30:1/10,100-401:2/100",
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			cset, err := ParseList(params.s)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := cset.CompactListString()
+			if got != params.want {
+				t.Errorf("unexpected compact list string: got %q, want %q", got, params.want)
+			}
+
+			if s, err := ParseList(got); err != nil || !s.Equal(cset) {
+				t.Errorf("unexpected round-trip: got %v (error: %v), want %v", s, err, cset)
+			}
+		})
+	}
+}
+
+func TestParseOptionsParseListTrimSpace(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    string
+		want CPUSet
+		err  *ParseError
+	}{
+		{
+			name: "trailing newline",
+			s:    "0-3\n",
+			want: rangeOf(0, 3),
+		},
+		{
+			name: "space after commas",
+			s:    " 0, 2-3 ,\t5\n",
+			want: Of(0, 2, 3, 5),
+		},
+		{
+			name: "blank",
+			s:    " \n",
+			want: CPUSet{},
+		},
+		{
+			name: `invalid element "x" at offset 4`,
+			s:    " 0, x\n",
+			err:  &ParseError{Input: " 0, x\n", Format: ListFormat, Offset: 4, Element: "x", Part: "x", Kind: ErrInvalidElement},
+		},
+		{
+			name: `invalid upper bound "x y" at offset 3`,
+			s:    "0, 1-x y",
+			err:  &ParseError{Input: "0, 1-x y", Format: ListFormat, Offset: 3, Element: "1-x y", Part: "x y", Kind: ErrInvalidUpperBound},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			switch got, err := (ParseOptions{TrimSpace: true}).ParseList(params.s); {
+			case err == nil && params.err != nil:
+				t.Error("expected error")
+			case err != nil && params.err == nil:
+				t.Errorf("unexpected error: %v", err)
+			case err != nil && params.err != nil && !equalParseError(err, params.err):
+				t.Errorf("unexpected error: got %#v, want %#v", err, params.err)
+			case err == nil && params.err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,7 @@
 package cpuset
 
 import (
-	"fmt"
 	"math/bits"
-	"slices"
 	"strconv"
 	"strings"
 )
@@ -13,59 +11,311 @@ const (
 	wordBitSize = 32
 )
 
+// MaskOptions describes a variant of the mask format. The zero value of a
+// MaskOptions describes the format specified in the [Linux cpuset(7) man
+// page]: comma-separated 32-bit words of 8 hexadecimal digits.
+//
+// [Linux cpuset(7) man page]: https://man7.org/linux/man-pages/man7/cpuset.7.html
+type MaskOptions struct {
+	// WordBitSize is the number of bits of each word, either 32 or 64.
+	// If zero, 32 is used.
+	WordBitSize uint
+
+	// Ungrouped omits the commas between words, writing the mask as a
+	// single hexadecimal number as taskset(1) does.
+	Ungrouped bool
+
+	// Prefix prepends "0x" to the mask. When parsing, the prefix is
+	// optional and may also be "0X".
+	Prefix bool
+
+	// Trim removes the leading zeros of the mask, keeping a single zero
+	// digit for an empty set.
+	Trim bool
+
+	// Width is the number of bits of the mask, such as the number of
+	// possible CPUs of the system, as the Linux kernel writes masks padded
+	// to nr_cpu_ids. When encoding, the mask is padded with zeros to hold
+	// Width bits. When decoding, the mask must hold exactly Width bits,
+	// rounded up to a hexadecimal digit, and its CPUs must be lower than
+	// Width. Width is ignored if Trim is set.
+	Width uint
+}
+
+// Validate reports whether o describes a supported variant of the mask
+// format. It returns [ErrUnsupportedWordSize] if o.WordBitSize is neither 0,
+// 32 nor 64.
+func (o MaskOptions) Validate() error {
+	if n := o.wordBitSize(); n != 32 && n != 64 {
+		return ErrUnsupportedWordSize
+	}
+
+	return nil
+}
+
+func (o MaskOptions) width() uint {
+	if o.Trim {
+		return 0
+	}
+
+	return o.Width
+}
+
+func (o MaskOptions) wordBitSize() uint {
+	if o.WordBitSize == 0 {
+		return wordBitSize
+	}
+
+	return o.WordBitSize
+}
+
 // ParseMask decodes s into a [CPUSet]. It returns an error if s is not a valid
 // mask string, as specified in the [Linux cpuset(7) man page] (see "Mask
 // Format").
 //
+// CPUs must be lower than [DefaultLimit], use [ParseOptions.ParseMask] to
+// change it.
+//
 // [Linux cpuset(7) man page]: https://man7.org/linux/man-pages/man7/cpuset.7.html
-func ParseMask(s string) (cset CPUSet, _ error) {
-	if s == "" {
+func ParseMask(s string) (CPUSet, error) {
+	return ParseOptions{}.ParseMask(s)
+}
+
+// ParseMask is like [ParseMask] but uses the options of o.
+func (o ParseOptions) ParseMask(s string) (cset CPUSet, _ error) {
+	input := s
+	s, pos := o.trimSpace(s)
+
+	width := o.Mask.width()
+	if s == "" && width == 0 {
 		return CPUSet{}, nil
 	}
 
-	words := strings.Split(s, ",")
-	overflow, offset := bits.Mul(uint(len(words)), wordBitSize)
+	n := o.Mask.wordBitSize()
+	if err := o.Mask.Validate(); err != nil {
+		return CPUSet{}, &ParseError{Input: input, Format: MaskFormat, Kind: ErrUnsupportedWordSize, wordBitSize: n}
+	}
+
+	hex := s
+	if o.Mask.Prefix && len(s) >= 2 && (s[:2] == "0x" || s[:2] == "0X") {
+		hex, pos = s[2:], pos+2
+	}
+
+	numWords := strings.Count(hex, ",") + 1
+	if o.Mask.Ungrouped {
+		numWords = max(1, (len(hex)+int(n/4)-1)/int(n/4))
+	}
+
+	overflow, offset := bits.Mul(uint(numWords), n)
 	if overflow > 0 {
-		return CPUSet{}, formatParseError(s, "offset value out of range")
+		return CPUSet{}, &ParseError{Input: input, Format: MaskFormat, Kind: ErrTooManyWords}
 	}
 
-	for _, word := range words {
-		offset -= wordBitSize
+	limit := o.limit()
+	if width > 0 {
+		firstWord, _, _ := o.Mask.cutWord(hex, n)
+		firstWord, _ = o.trimSpace(firstWord)
+		got := (uint(numWords)-1)*n + uint(len(firstWord))*4
+		if want := (width + 3) / 4 * 4; got != want {
+			return CPUSet{}, &ParseError{Input: input, Format: MaskFormat, Kind: ErrWidthMismatch, Err: &WidthError{Width: got, Want: want}}
+		}
 
-		ui64, err := strconv.ParseUint(word, wordBase, wordBitSize)
-		if err != nil {
-			return CPUSet{}, formatParseError(s, fmt.Sprintf("invalid 32-bit word %q", word))
+		limit = min(limit, width)
+	}
+
+	for i, rest, more := 0, hex, true; more; i++ {
+		var rawWord string
+		rawWord, rest, more = o.Mask.cutWord(rest, n)
+
+		word, leading := o.trimSpace(rawWord)
+		perr := ParseError{Input: input, Format: MaskFormat, Offset: pos + leading, Element: word, wordBitSize: n}
+		pos += len(rawWord)
+		if !o.Mask.Ungrouped {
+			pos++
 		}
 
-		cpuMask := uint32(ui64)
+		offset -= n
 
-		for pos := range uint(wordBitSize) {
-			if cpuMask&(1<<pos) != 0 {
-				cset.Add(pos + offset)
-			}
+		ui64, err := strconv.ParseUint(word, wordBase, int(n))
+		if err != nil || width > 0 && i > 0 && uint(len(word)) != n/4 {
+			return CPUSet{}, perr.with(ErrInvalidWord, word, nil)
+		}
+
+		if ui64 == 0 {
+			continue
 		}
+
+		// Words are processed from the most significant one, the first
+		// non-zero word thus holds the highest CPU of the set.
+		cpu := offset + uint(bits.Len64(ui64)) - 1
+		if cpu >= limit {
+			return CPUSet{}, perr.with(ErrOutOfRange, word, &RangeError{CPU: cpu, Limit: limit})
+		}
+
+		cset.grow(cpu)
+		cset.blocks[offset/blockBitSize] |= ui64 << (offset % blockBitSize)
 	}
 
 	return cset, nil
 }
 
+// ParseAnyMask decodes s into a [CPUSet], detecting the variant of the mask
+// format s is written in: hexadecimal numbers with or without the "0x"
+// prefix, such as the masks of taskset(1), and comma-separated words of 32
+// or 64 bits. It returns the detected variant along with the [CPUSet].
+//
+// A string with neither commas nor the exact length of a 32-bit word is
+// decoded as a single hexadecimal number. An empty string is decoded as an
+// empty set written in the default variant.
+func ParseAnyMask(s string) (CPUSet, MaskOptions, error) {
+	return ParseOptions{}.ParseAnyMask(s)
+}
+
+// ParseAnyMask is like [ParseAnyMask] but uses the options of o, except for
+// the mask variant.
+func (o ParseOptions) ParseAnyMask(s string) (CPUSet, MaskOptions, error) {
+	var opts MaskOptions
+
+	hex, _ := o.trimSpace(s)
+	if len(hex) >= 2 && (hex[:2] == "0x" || hex[:2] == "0X") {
+		hex, opts.Prefix = hex[2:], true
+	}
+
+	words := strings.Split(hex, ",")
+	switch {
+	case hex == "":
+	case len(words) == 1 && len(hex) != wordBitSize/4:
+		opts.Ungrouped = true
+		opts.Trim = hex == "0" || hex[0] != '0'
+	default:
+		for _, word := range words {
+			if len(word) > wordBitSize/4 {
+				opts.WordBitSize = 64
+			}
+		}
+
+		opts.Trim = len(words[0]) < int(opts.wordBitSize()/4)
+	}
+
+	o.Mask = opts
+	cset, err := o.ParseMask(s)
+	if err != nil {
+		return CPUSet{}, MaskOptions{}, err
+	}
+
+	return cset, opts, nil
+}
+
 // MaskString encodes s into a mask string.
 func (s *CPUSet) MaskString() string {
-	if s.Len() == 0 {
-		return ""
+	var buf [64]byte
+	return string(s.AppendMask(buf[:0]))
+}
+
+// AppendMask appends the mask string encoding of s to b and returns the
+// extended buffer.
+func (s *CPUSet) AppendMask(b []byte) []byte {
+	return s.AppendFormatMask(b, MaskOptions{})
+}
+
+// MaskStringWidth encodes s into a mask string padded with zeros to hold
+// nbits bits, as the Linux kernel writes masks of nr_cpu_ids bits, e.g.
+// "00000000,00000003" for CPUs 0 and 1 of a 64-CPU system.
+func (s *CPUSet) MaskStringWidth(nbits uint) string {
+	return s.FormatMask(MaskOptions{Width: nbits})
+}
+
+// FormatMask encodes s into a mask string written in the variant described
+// by opts. If opts.WordBitSize is not supported (see [MaskOptions.Validate]),
+// 32-bit words are written instead.
+func (s *CPUSet) FormatMask(opts MaskOptions) string {
+	var buf [64]byte
+	return string(s.AppendFormatMask(buf[:0], opts))
+}
+
+// AppendFormatMask is like [CPUSet.FormatMask] but appends the mask string
+// to b and returns the extended buffer.
+func (s *CPUSet) AppendFormatMask(b []byte, opts MaskOptions) []byte {
+	if opts.Validate() != nil {
+		opts.WordBitSize = 0
 	}
 
-	cpus := s.UnsortedList()
-	cpuMasks := make([]uint32, slices.Max(cpus)/wordBitSize+1)
-	for _, cpu := range cpus {
-		i, pos := int(cpu/wordBitSize), cpu%wordBitSize
-		cpuMasks[len(cpuMasks)-i-1] |= 1 << pos
+	n := opts.wordBitSize()
+
+	// The mask holds width bits: enough for the highest CPU of the set,
+	// widened to opts.Width. Only the first word may then be narrower than
+	// n bits.
+	var width uint
+	if len(s.blocks) > 0 {
+		width = s.max() + 1
+	}
+
+	if opts.width() > 0 {
+		width = max(width, opts.width())
+	} else {
+		width = (width + n - 1) / n * n
 	}
 
-	words := make([]string, len(cpuMasks))
-	for i, cpuMask := range cpuMasks {
-		words[i] = fmt.Sprintf("%08x", cpuMask)
+	// An empty set is written as an empty mask, unless leading zeros are
+	// trimmed: a single zero digit is then kept, as for a zero word.
+	if width == 0 {
+		if !opts.Trim {
+			return b
+		}
+
+		width = n
+	}
+
+	if opts.Prefix {
+		b = append(b, "0x"...)
+	}
+
+	words := int((width + n - 1) / n)
+	firstDigits := int((width - uint(words-1)*n + 3) / 4)
+	for i := words - 1; i >= 0; i-- {
+		var cpuMask uint64
+		if offset := uint(i) * n; offset/blockBitSize < uint(len(s.blocks)) {
+			cpuMask = s.blocks[offset/blockBitSize] >> (offset % blockBitSize)
+		}
+		if n < blockBitSize {
+			cpuMask &= 1<<n - 1
+		}
+
+		switch {
+		case i == words-1 && opts.Trim:
+			b = appendWord(b, cpuMask, 1)
+		case i == words-1:
+			b = appendWord(b, cpuMask, firstDigits)
+		case !opts.Ungrouped:
+			b = append(b, ',')
+			fallthrough
+		default:
+			b = appendWord(b, cpuMask, int(n/4))
+		}
+	}
+
+	return b
+}
+
+// appendWord appends the hexadecimal encoding of word to b, left-padded with
+// zeros to the given number of digits.
+func appendWord(b []byte, word uint64, digits int) []byte {
+	for range digits - max(1, (bits.Len64(word)+3)/4) {
+		b = append(b, '0')
+	}
+
+	return strconv.AppendUint(b, word, wordBase)
+}
+
+// cutWord slices s around its first word of n bits, returning the text
+// before and after it. The found result reports whether s holds more words.
+// Ungrouped masks are cut from the end, so that only the first word may be
+// shorter than n/4 digits.
+func (o MaskOptions) cutWord(s string, n uint) (before, after string, found bool) {
+	if !o.Ungrouped {
+		return strings.Cut(s, ",")
 	}
 
-	return strings.Join(words, ",")
+	i := max(0, (len(s)-1)%int(n/4)+1)
+	return s[:i], s[i:], len(s) > i
 }
@@ -1,6 +1,8 @@
 package cpuset
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -9,22 +11,32 @@ func TestParseMask(t *testing.T) {
 		name string
 		s    string
 		want CPUSet
-		err  error
+		err  *ParseError
 	}{
 		{
 			name: `invalid 32-bit word ""`,
 			s:    ",",
-			err:  formatParseError(",", `invalid 32-bit word ""`),
+			err:  &ParseError{Input: ",", Format: MaskFormat, Element: "", Part: "", Kind: ErrInvalidWord, wordBitSize: 32},
 		},
 		{
 			name: `invalid 32-bit word "100000000"`,
 			s:    "100000000",
-			err:  formatParseError("100000000", `invalid 32-bit word "100000000"`),
+			err:  &ParseError{Input: "100000000", Format: MaskFormat, Element: "100000000", Part: "100000000", Kind: ErrInvalidWord, wordBitSize: 32},
+		},
+		{
+			name: `invalid 32-bit word "xxxxxxxx" at offset 9`,
+			s:    "00000001,xxxxxxxx",
+			err:  &ParseError{Input: "00000001,xxxxxxxx", Format: MaskFormat, Offset: 9, Element: "xxxxxxxx", Part: "xxxxxxxx", Kind: ErrInvalidWord, wordBitSize: 32},
 		},
 		{
 			name: `invalid 32-bit word "xxxxxxxx"`,
 			s:    "xxxxxxxx",
-			err:  formatParseError("xxxxxxxx", `invalid 32-bit word "xxxxxxxx"`),
+			err:  &ParseError{Input: "xxxxxxxx", Format: MaskFormat, Element: "xxxxxxxx", Part: "xxxxxxxx", Kind: ErrInvalidWord, wordBitSize: 32},
+		},
+		{
+			name: "CPU 8192 out of range",
+			s:    "00000001" + strings.Repeat(",00000000", 256),
+			err:  &ParseError{Input: "00000001" + strings.Repeat(",00000000", 256), Format: MaskFormat, Element: "00000001", Part: "00000001", Kind: ErrOutOfRange, Err: &RangeError{CPU: 8192, Limit: DefaultLimit}, wordBitSize: 32},
 		},
 		{
 			name: "no bit set",
@@ -68,8 +80,131 @@ func TestParseMask(t *testing.T) {
 				t.Error("expected error")
 			case err != nil && params.err == nil:
 				t.Errorf("unexpected error: %v", err)
-			case err != nil && params.err != nil && err.Error() != params.err.Error():
-				t.Errorf("unexpected error: got %v, want %v", err, params.err)
+			case err != nil && params.err != nil && !equalParseError(err, params.err):
+				t.Errorf("unexpected error: got %#v, want %#v", err, params.err)
+			case err == nil && params.err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestParseOptionsParseMask(t *testing.T) {
+	for _, params := range []struct {
+		name  string
+		limit uint
+		s     string
+		want  CPUSet
+		err   *RangeError
+	}{
+		{
+			name:  "CPU 4 out of range [0, 4)",
+			limit: 4,
+			s:     "00000017",
+			err:   &RangeError{CPU: 4, Limit: 4},
+		},
+		{
+			name:  "CPU 63 out of range [0, 4)",
+			limit: 4,
+			s:     "80000000,00000001",
+			err:   &RangeError{CPU: 63, Limit: 4},
+		},
+		{
+			name:  "bits 0, 1, 2, and 3 set with leading zero words",
+			limit: 4,
+			s:     "00000000,00000000,0000000f",
+			want:  Of(0, 1, 2, 3),
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			var rangeErr *RangeError
+			switch got, err := (ParseOptions{Limit: params.limit}).ParseMask(params.s); {
+			case err == nil && params.err != nil:
+				t.Error("expected error")
+			case err != nil && params.err == nil:
+				t.Errorf("unexpected error: %v", err)
+			case err != nil && !errors.As(err, &rangeErr):
+				t.Errorf("unexpected error type: %T", err)
+			case err != nil && *rangeErr != *params.err:
+				t.Errorf("unexpected error: got %v, want %v", rangeErr, params.err)
+			case err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestParseOptionsParseMaskVariants(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		opts MaskOptions
+		s    string
+		want CPUSet
+		err  *ParseError
+	}{
+		{
+			name: "64-bit words",
+			opts: MaskOptions{WordBitSize: 64},
+			s:    "0000000000000001,8000000000000000",
+			want: Of(63, 64),
+		},
+		{
+			name: "64-bit words with trimmed leading zeros",
+			opts: MaskOptions{WordBitSize: 64},
+			s:    "1,0000000000000000",
+			want: Of(64),
+		},
+		{
+			name: "ungrouped",
+			opts: MaskOptions{Ungrouped: true},
+			s:    "3f00000000",
+			want: rangeOf(32, 37),
+		},
+		{
+			name: "ungrouped with prefix",
+			opts: MaskOptions{Ungrouped: true, Prefix: true},
+			s:    "0x3f00000000",
+			want: rangeOf(32, 37),
+		},
+		{
+			name: "ungrouped with uppercase prefix",
+			opts: MaskOptions{Ungrouped: true, Prefix: true},
+			s:    "0Xff",
+			want: rangeOf(0, 7),
+		},
+		{
+			name: "ungrouped with optional prefix omitted",
+			opts: MaskOptions{Ungrouped: true, Prefix: true},
+			s:    "ff",
+			want: rangeOf(0, 7),
+		},
+		{
+			name: "prefix not enabled",
+			opts: MaskOptions{Ungrouped: true},
+			s:    "0xff",
+			err:  &ParseError{Input: "0xff", Format: MaskFormat, Element: "0xff", Part: "0xff", Kind: ErrInvalidWord, wordBitSize: 32},
+		},
+		{
+			name: `invalid 32-bit word "x0000000" at offset 3`,
+			opts: MaskOptions{Ungrouped: true, Prefix: true},
+			s:    "0xfx0000000",
+			err:  &ParseError{Input: "0xfx0000000", Format: MaskFormat, Offset: 3, Element: "x0000000", Part: "x0000000", Kind: ErrInvalidWord, wordBitSize: 32},
+		},
+		{
+			name: `invalid 64-bit word "10000000000000000"`,
+			opts: MaskOptions{WordBitSize: 64},
+			s:    "10000000000000000",
+			err:  &ParseError{Input: "10000000000000000", Format: MaskFormat, Element: "10000000000000000", Part: "10000000000000000", Kind: ErrInvalidWord, wordBitSize: 64},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			switch got, err := (ParseOptions{Mask: params.opts}).ParseMask(params.s); {
+			case err == nil && params.err != nil:
+				t.Error("expected error")
+			case err != nil && params.err == nil:
+				t.Errorf("unexpected error: %v", err)
+			case err != nil && params.err != nil && !equalParseError(err, params.err):
+				t.Errorf("unexpected error: got %#v, want %#v", err, params.err)
 			case err == nil && params.err == nil && !got.Equal(params.want):
 				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
 			}
@@ -77,6 +212,111 @@ func TestParseMask(t *testing.T) {
 	}
 }
 
+func TestParseOptionsParseMaskUnsupportedWordSize(t *testing.T) {
+	want := &ParseError{Input: "ff", Format: MaskFormat, Kind: ErrUnsupportedWordSize, wordBitSize: 16}
+	switch _, err := (ParseOptions{Mask: MaskOptions{WordBitSize: 16}}).ParseMask("ff"); {
+	case !errors.Is(err, ErrUnsupportedWordSize):
+		t.Errorf("unexpected error: got %v, want %v", err, ErrUnsupportedWordSize)
+	case !equalParseError(err, want):
+		t.Errorf("unexpected error: got %#v, want %#v", err, want)
+	}
+}
+
+func TestMaskOptionsValidate(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		opts MaskOptions
+		want error
+	}{
+		{"default word size", MaskOptions{}, nil},
+		{"32-bit words", MaskOptions{WordBitSize: 32}, nil},
+		{"64-bit words", MaskOptions{WordBitSize: 64}, nil},
+		{"unsupported word size", MaskOptions{WordBitSize: 16}, ErrUnsupportedWordSize},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if err := params.opts.Validate(); !errors.Is(err, params.want) {
+				t.Errorf("unexpected error: got %v, want %v", err, params.want)
+			}
+		})
+	}
+}
+
+func TestParseAnyMask(t *testing.T) {
+	for _, params := range []struct {
+		name     string
+		s        string
+		want     CPUSet
+		wantOpts MaskOptions
+	}{
+		{
+			name:     "taskset mask",
+			s:        "0x3f00000000",
+			want:     rangeOf(32, 37),
+			wantOpts: MaskOptions{Ungrouped: true, Prefix: true, Trim: true},
+		},
+		{
+			name:     "short hexadecimal number",
+			s:        "ff",
+			want:     rangeOf(0, 7),
+			wantOpts: MaskOptions{Ungrouped: true, Trim: true},
+		},
+		{
+			name:     "long hexadecimal number",
+			s:        "100000000",
+			want:     Of(32),
+			wantOpts: MaskOptions{Ungrouped: true, Trim: true},
+		},
+		{
+			name:     "empty",
+			s:        "",
+			want:     CPUSet{},
+			wantOpts: MaskOptions{},
+		},
+		{
+			name:     "trimmed empty mask",
+			s:        "0x0",
+			want:     CPUSet{},
+			wantOpts: MaskOptions{Ungrouped: true, Prefix: true, Trim: true},
+		},
+		{
+			name:     "32-bit words",
+			s:        "000000ff,00000000",
+			want:     rangeOf(32, 39),
+			wantOpts: MaskOptions{},
+		},
+		{
+			name:     "32-bit words with trimmed leading zeros",
+			s:        "ff,00000000",
+			want:     rangeOf(32, 39),
+			wantOpts: MaskOptions{Trim: true},
+		},
+		{
+			name:     "64-bit words",
+			s:        "00000000000000ff,0000000000000000",
+			want:     rangeOf(64, 71),
+			wantOpts: MaskOptions{WordBitSize: 64},
+		},
+		{
+			name:     "64-bit words with trimmed leading zeros",
+			s:        "ff,0000000000000000",
+			want:     rangeOf(64, 71),
+			wantOpts: MaskOptions{WordBitSize: 64, Trim: true},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			got, gotOpts, err := ParseAnyMask(params.s)
+			switch {
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			case gotOpts != params.wantOpts:
+				t.Errorf("unexpected mask options: got %+v, want %+v", gotOpts, params.wantOpts)
+			}
+		})
+	}
+}
+
 func TestMaskString(t *testing.T) {
 	for _, params := range []struct {
 		name string
@@ -126,3 +366,344 @@ func TestMaskString(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatMask(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		s    CPUSet
+		opts MaskOptions
+		want string
+	}{
+		{
+			name: "no bit set",
+			s:    CPUSet{},
+			opts: MaskOptions{Prefix: true},
+			want: "",
+		},
+		{
+			name: "64-bit words",
+			s:    Of(0, 64),
+			opts: MaskOptions{WordBitSize: 64},
+			want: "0000000000000001,0000000000000001",
+		},
+		{
+			name: "64-bit words with trimmed leading zeros",
+			s:    Of(0, 64),
+			opts: MaskOptions{WordBitSize: 64, Trim: true},
+			want: "1,0000000000000001",
+		},
+		{
+			name: "ungrouped",
+			s:    rangeOf(32, 37),
+			opts: MaskOptions{Ungrouped: true},
+			want: "0000003f00000000",
+		},
+		{
+			name: "ungrouped with prefix and trimmed leading zeros",
+			s:    rangeOf(32, 37),
+			opts: MaskOptions{Ungrouped: true, Prefix: true, Trim: true},
+			want: "0x3f00000000",
+		},
+		{
+			name: "trimmed leading zeros",
+			s:    Of(32),
+			opts: MaskOptions{Trim: true},
+			want: "1,00000000",
+		},
+		{
+			name: "unsupported word size",
+			s:    Of(0, 32),
+			opts: MaskOptions{WordBitSize: 16},
+			want: "00000001,00000001",
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.s.FormatMask(params.opts); got != params.want {
+				t.Errorf("unexpected mask string: got %q, want %q", got, params.want)
+			}
+		})
+	}
+}
+
+func TestFormatMaskEmpty(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		opts MaskOptions
+		want string
+	}{
+		{"default", MaskOptions{}, ""},
+		{"64-bit words", MaskOptions{WordBitSize: 64}, ""},
+		{"ungrouped", MaskOptions{Ungrouped: true}, ""},
+		{"prefix", MaskOptions{Prefix: true}, ""},
+		{"trimmed leading zeros", MaskOptions{Trim: true}, "0"},
+		{"prefix and trimmed leading zeros", MaskOptions{Prefix: true, Trim: true}, "0x0"},
+		{"ungrouped with prefix and trimmed leading zeros", MaskOptions{Ungrouped: true, Prefix: true, Trim: true}, "0x0"},
+		{"width", MaskOptions{Width: 40}, "00,00000000"},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			var s CPUSet
+			mask := s.FormatMask(params.opts)
+			if mask != params.want {
+				t.Fatalf("unexpected mask string: got %q, want %q", mask, params.want)
+			}
+
+			got, err := ParseOptions{Mask: params.opts}.ParseMask(mask)
+			switch {
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case got.Len() != 0:
+				t.Errorf("unexpected cpuset: got %v, want empty", got)
+			}
+		})
+	}
+}
+
+func TestMaskStringWidth(t *testing.T) {
+	for _, params := range []struct {
+		name  string
+		s     CPUSet
+		nbits uint
+		want  string
+	}{
+		{
+			name:  "no bit set",
+			s:     CPUSet{},
+			nbits: 64,
+			want:  "00000000,00000000",
+		},
+		{
+			name:  "bits 0 and 1 set on 64 bits",
+			s:     Of(0, 1),
+			nbits: 64,
+			want:  "00000000,00000003",
+		},
+		{
+			name:  "bits 0 and 1 set on 4 bits",
+			s:     Of(0, 1),
+			nbits: 4,
+			want:  "3",
+		},
+		{
+			name:  "bit 0 set on 36 bits",
+			s:     Of(0),
+			nbits: 36,
+			want:  "0,00000001",
+		},
+		{
+			name:  "bit 0 set on 42 bits",
+			s:     Of(0),
+			nbits: 42,
+			want:  "000,00000001",
+		},
+		{
+			name:  "bit 40 set beyond 8 bits",
+			s:     Of(40),
+			nbits: 8,
+			want:  "100,00000000",
+		},
+		{
+			name:  "no width",
+			s:     Of(0),
+			nbits: 0,
+			want:  "00000001",
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.s.MaskStringWidth(params.nbits); got != params.want {
+				t.Errorf("unexpected mask string: got %q, want %q", got, params.want)
+			}
+		})
+	}
+}
+
+func TestParseOptionsParseMaskWidth(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		opts MaskOptions
+		s    string
+		want CPUSet
+		err  *ParseError
+	}{
+		{
+			name: "64 bits",
+			opts: MaskOptions{Width: 64},
+			s:    "00000000,00000003",
+			want: Of(0, 1),
+		},
+		{
+			name: "36 bits",
+			opts: MaskOptions{Width: 36},
+			s:    "1,00000000",
+			want: Of(32),
+		},
+		{
+			name: "ungrouped 64 bits",
+			opts: MaskOptions{Width: 64, Ungrouped: true},
+			s:    "0000000000000003",
+			want: Of(0, 1),
+		},
+		{
+			name: "trimmed mask ignoring width",
+			opts: MaskOptions{Width: 64, Trim: true},
+			s:    "3",
+			want: Of(0, 1),
+		},
+		{
+			name: "mask too narrow",
+			opts: MaskOptions{Width: 64},
+			s:    "00000003",
+			err:  &ParseError{Input: "00000003", Format: MaskFormat, Kind: ErrWidthMismatch, Err: &WidthError{Width: 32, Want: 64}},
+		},
+		{
+			name: "mask too wide",
+			opts: MaskOptions{Width: 36},
+			s:    "00000001,00000000",
+			err:  &ParseError{Input: "00000001,00000000", Format: MaskFormat, Kind: ErrWidthMismatch, Err: &WidthError{Width: 64, Want: 36}},
+		},
+		{
+			name: "empty mask",
+			opts: MaskOptions{Width: 4},
+			s:    "",
+			err:  &ParseError{Input: "", Format: MaskFormat, Kind: ErrWidthMismatch, Err: &WidthError{Width: 0, Want: 4}},
+		},
+		{
+			name: `invalid 32-bit word "3" at offset 11`,
+			opts: MaskOptions{Width: 68},
+			s:    "0,00000000,3",
+			err:  &ParseError{Input: "0,00000000,3", Format: MaskFormat, Offset: 11, Element: "3", Part: "3", Kind: ErrInvalidWord, wordBitSize: 32},
+		},
+		{
+			name: "CPU 5 out of range [0, 5)",
+			opts: MaskOptions{Width: 5},
+			s:    "20",
+			err:  &ParseError{Input: "20", Format: MaskFormat, Element: "20", Part: "20", Kind: ErrOutOfRange, Err: &RangeError{CPU: 5, Limit: 5}, wordBitSize: 32},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			switch got, err := (ParseOptions{Mask: params.opts}).ParseMask(params.s); {
+			case err == nil && params.err != nil:
+				t.Error("expected error")
+			case err != nil && params.err == nil:
+				t.Errorf("unexpected error: %v", err)
+			case err != nil && params.err != nil && !equalParseError(err, params.err):
+				t.Errorf("unexpected error: got %#v, want %#v", err, params.err)
+			case err == nil && params.err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestParseOptionsParseMaskTrimSpace(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		opts ParseOptions
+		s    string
+		want CPUSet
+		err  *ParseError
+	}{
+		{
+			name: "trailing newline",
+			opts: ParseOptions{TrimSpace: true},
+			s:    "00000000,00000003\n",
+			want: Of(0, 1),
+		},
+		{
+			name: "space after commas",
+			opts: ParseOptions{TrimSpace: true},
+			s:    "00000001, 00000003",
+			want: Of(0, 1, 32),
+		},
+		{
+			name: "blank",
+			opts: ParseOptions{TrimSpace: true},
+			s:    "\n",
+			want: CPUSet{},
+		},
+		{
+			name: "ungrouped with prefix and trailing newline",
+			opts: ParseOptions{TrimSpace: true, Mask: MaskOptions{Ungrouped: true, Prefix: true}},
+			s:    "0x3f00000000\n",
+			want: rangeOf(32, 37),
+		},
+		{
+			name: "width and trailing newline",
+			opts: ParseOptions{TrimSpace: true, Mask: MaskOptions{Width: 64}},
+			s:    "00000000,00000003\n",
+			want: Of(0, 1),
+		},
+		{
+			name: `invalid 32-bit word "x" at offset 11`,
+			opts: ParseOptions{TrimSpace: true},
+			s:    " 00000001, x",
+			err:  &ParseError{Input: " 00000001, x", Format: MaskFormat, Offset: 11, Element: "x", Part: "x", Kind: ErrInvalidWord, wordBitSize: 32},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			switch got, err := params.opts.ParseMask(params.s); {
+			case err == nil && params.err != nil:
+				t.Error("expected error")
+			case err != nil && params.err == nil:
+				t.Errorf("unexpected error: %v", err)
+			case err != nil && params.err != nil && !equalParseError(err, params.err):
+				t.Errorf("unexpected error: got %#v, want %#v", err, params.err)
+			case err == nil && params.err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetAppendMask(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		b    []byte
+		s    CPUSet
+		want string
+	}{
+		{
+			name: "no bit set",
+			b:    []byte("mask="),
+			s:    CPUSet{},
+			want: "mask=",
+		},
+		{
+			name: "bits 0, 1, 2, 4, 8, 16, 32, and 64 set",
+			b:    []byte("mask="),
+			s:    Of(0, 1, 2, 4, 8, 16, 32, 64),
+			want: "mask=00000001,00000001,00010117",
+		},
+		{
+			name: "nil buffer",
+			s:    Of(31),
+			want: "80000000",
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			if got := params.s.AppendMask(params.b); string(got) != params.want {
+				t.Errorf("unexpected mask string: got %q, want %q", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetAppendFormatMask(t *testing.T) {
+	s := rangeOf(32, 37)
+	want := "cpus=0x3f00000000"
+	if got := s.AppendFormatMask([]byte("cpus="), MaskOptions{Ungrouped: true, Prefix: true, Trim: true}); string(got) != want {
+		t.Errorf("unexpected mask string: got %q, want %q", got, want)
+	}
+}
+
+func TestCPUSetAppendAllocs(t *testing.T) {
+	s, _ := benchmarkCPUSets()
+	buf := make([]byte, 0, 4096)
+
+	if allocs := testing.AllocsPerRun(100, func() { s.AppendList(buf[:0]) }); allocs != 0 {
+		t.Errorf("unexpected allocations for AppendList: got %v, want 0", allocs)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { s.AppendMask(buf[:0]) }); allocs != 0 {
+		t.Errorf("unexpected allocations for AppendMask: got %v, want 0", allocs)
+	}
+}
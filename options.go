@@ -0,0 +1,85 @@
+package cpuset
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A Format identifies the format of a cpuset string, as specified in the
+// [Linux cpuset(7) man page] (see "Formats").
+//
+// [Linux cpuset(7) man page]: https://man7.org/linux/man-pages/man7/cpuset.7.html
+type Format int
+
+const (
+	ListFormat Format = iota // the "List" format, e.g. "0-4,9"
+	MaskFormat               // the "Mask" format, e.g. "00000001,ffffffff"
+)
+
+func (f Format) String() string {
+	switch f {
+	case ListFormat:
+		return "list"
+	case MaskFormat:
+		return "mask"
+	default:
+		return "Format(" + strconv.Itoa(int(f)) + ")"
+	}
+}
+
+// DefaultLimit is the number of CPUs accepted when parsing, unless specified
+// otherwise in [ParseOptions]. It matches the highest NR_CPUS the Linux
+// kernel can be configured with.
+const DefaultLimit = 8192
+
+// ParseOptions configures the decoding of cpuset strings.
+// The zero value of a ParseOptions is ready to use.
+type ParseOptions struct {
+	// Limit is the number of CPUs accepted: parsing fails with a
+	// [ParseError] of kind [ErrOutOfRange], wrapping a [RangeError], if a
+	// CPU greater than or equal to Limit is found.
+	// If zero, DefaultLimit is used.
+	Limit uint
+
+	// NumCPUs is the number of possible CPUs of the system the cpuset
+	// string is intended for. When non-zero, CPUs must also be lower than
+	// NumCPUs and list strings may use the keywords of the Linux kernel
+	// command-line: "N" for the last CPU (NumCPUs-1), "all" for the range
+	// of all CPUs and "none" for an empty list.
+	NumCPUs uint
+
+	// Mask is the variant of the mask format decoded by
+	// [ParseOptions.ParseMask].
+	Mask MaskOptions
+
+	// TrimSpace ignores white space around the cpuset string and around
+	// each of its elements or words, as the Linux kernel does, so that
+	// strings read from files such as "0-3\n" or "0, 2" are accepted. A
+	// blank string then decodes into an empty [CPUSet].
+	TrimSpace bool
+}
+
+func (o ParseOptions) limit() uint {
+	limit := o.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	if o.NumCPUs > 0 {
+		limit = min(limit, o.NumCPUs)
+	}
+
+	return limit
+}
+
+// trimSpace returns s without its surrounding white space if enabled,
+// along with the number of bytes removed from the start of s.
+func (o ParseOptions) trimSpace(s string) (string, int) {
+	if !o.TrimSpace {
+		return s, 0
+	}
+
+	trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+	return strings.TrimRightFunc(trimmed, unicode.IsSpace), len(s) - len(trimmed)
+}
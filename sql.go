@@ -0,0 +1,99 @@
+package cpuset
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Scan implements the [database/sql.Scanner] interface. It accepts list
+// strings, mask strings and integers, bit n of an integer standing for CPU
+// n. Strings made only of comma-separated 32-bit words of 8 hexadecimal
+// digits, such as "0000000f", are decoded as mask strings, any other string
+// as a list string.
+func (s *CPUSet) Scan(src any) error {
+	var (
+		cset CPUSet
+		err  error
+	)
+
+	switch src := src.(type) {
+	case string:
+		cset, err = scanString(src)
+	case []byte:
+		cset, err = scanString(string(src))
+	case int64:
+		cset.blocks = []uint64{uint64(src)}
+		cset.trim()
+	case nil:
+		return errors.New("cpuset: converting NULL to CPUSet is unsupported, use NullCPUSet")
+	default:
+		return fmt.Errorf("cpuset: converting %T to CPUSet is unsupported", src)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*s = cset
+	return nil
+}
+
+// Value implements the [database/sql/driver.Valuer] interface. It encodes s
+// into a list string.
+func (s CPUSet) Value() (driver.Value, error) {
+	return s.ListString(), nil
+}
+
+func scanString(s string) (CPUSet, error) {
+	if isKernelMask(s) {
+		return ParseMask(s)
+	}
+
+	return ParseList(s)
+}
+
+// isKernelMask reports whether s is made only of comma-separated words of 8
+// hexadecimal digits, as the Linux kernel writes mask strings.
+func isKernelMask(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, word := range strings.Split(s, ",") {
+		if len(word) != wordBitSize/4 || strings.Trim(word, "0123456789abcdefABCDEF") != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// A NullCPUSet represents a [CPUSet] that may be NULL. It implements the
+// [database/sql.Scanner] and [database/sql/driver.Valuer] interfaces, so it
+// can be used as a scan destination and a query argument.
+type NullCPUSet struct {
+	CPUSet CPUSet
+	Valid  bool // Valid is true if CPUSet is not NULL
+}
+
+// Scan implements the [database/sql.Scanner] interface.
+func (n *NullCPUSet) Scan(src any) error {
+	if src == nil {
+		n.CPUSet, n.Valid = CPUSet{}, false
+		return nil
+	}
+
+	n.Valid = true
+	return n.CPUSet.Scan(src)
+}
+
+// Value implements the [database/sql/driver.Valuer] interface.
+func (n NullCPUSet) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	return n.CPUSet.Value()
+}
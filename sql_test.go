@@ -0,0 +1,189 @@
+package cpuset
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+// echoDriver is a stand-in [database/sql/driver.Driver] whose queries return
+// a single row made of their arguments.
+type echoDriver struct{}
+
+func (echoDriver) Open(string) (driver.Conn, error) { return echoConn{}, nil }
+
+type echoConn struct{}
+
+func (echoConn) Prepare(string) (driver.Stmt, error) { return echoStmt{}, nil }
+func (echoConn) Close() error                        { return nil }
+func (echoConn) Begin() (driver.Tx, error)           { return nil, errors.New("unsupported") }
+
+type echoStmt struct{}
+
+func (echoStmt) Close() error  { return nil }
+func (echoStmt) NumInput() int { return -1 }
+
+func (echoStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("unsupported")
+}
+
+func (echoStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &echoRows{args: args}, nil
+}
+
+type echoRows struct {
+	args []driver.Value
+	done bool
+}
+
+func (r *echoRows) Columns() []string { return make([]string, len(r.args)) }
+func (r *echoRows) Close() error      { return nil }
+
+func (r *echoRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+
+	r.done = true
+	copy(dest, r.args)
+	return nil
+}
+
+func init() {
+	sql.Register("cpuset-echo", echoDriver{})
+}
+
+func openEchoDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("cpuset-echo", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestCPUSetScan(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		src  any
+		want CPUSet
+		err  bool
+	}{
+		{
+			name: "NULL",
+			src:  nil,
+			err:  true,
+		},
+		{
+			name: "unsupported type",
+			src:  1.5,
+			err:  true,
+		},
+		{
+			name: "invalid list string",
+			src:  "a",
+			err:  true,
+		},
+		{
+			name: "empty string",
+			src:  "",
+			want: CPUSet{},
+		},
+		{
+			name: "list string",
+			src:  "0-3,8",
+			want: Of(0, 1, 2, 3, 8),
+		},
+		{
+			name: "list string out of range",
+			src:  []byte("8192"),
+			err:  true,
+		},
+		{
+			name: "list bytes",
+			src:  []byte("0-3,8"),
+			want: Of(0, 1, 2, 3, 8),
+		},
+		{
+			name: "mask string",
+			src:  "00000001,0000010f",
+			want: Of(0, 1, 2, 3, 8, 32),
+		},
+		{
+			name: "mask bytes",
+			src:  []byte("0000010f"),
+			want: Of(0, 1, 2, 3, 8),
+		},
+		{
+			name: "integer",
+			src:  int64(0x10f),
+			want: Of(0, 1, 2, 3, 8),
+		},
+		{
+			name: "negative integer",
+			src:  int64(-1 << 63),
+			want: Of(63),
+		},
+		{
+			name: "zero integer",
+			src:  int64(0),
+			want: CPUSet{},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			var got CPUSet
+			switch err := openEchoDB(t).QueryRow("echo", params.src).Scan(&got); {
+			case err == nil && params.err:
+				t.Error("expected error")
+			case err != nil && !params.err:
+				t.Errorf("unexpected error: %v", err)
+			case err == nil && !got.Equal(params.want):
+				t.Errorf("unexpected cpuset: got %v, want %v", got, params.want)
+			}
+		})
+	}
+}
+
+func TestCPUSetValue(t *testing.T) {
+	var got string
+	if err := openEchoDB(t).QueryRow("echo", Of(0, 1, 2, 3, 8)).Scan(&got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := "0-3,8"; got != want {
+		t.Errorf("unexpected value: got %q, want %q", got, want)
+	}
+}
+
+func TestNullCPUSet(t *testing.T) {
+	for _, params := range []struct {
+		name string
+		arg  NullCPUSet
+	}{
+		{
+			name: "NULL",
+			arg:  NullCPUSet{},
+		},
+		{
+			name: "empty",
+			arg:  NullCPUSet{Valid: true},
+		},
+		{
+			name: "not empty",
+			arg:  NullCPUSet{CPUSet: Of(0, 1, 2, 3, 8), Valid: true},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			got := NullCPUSet{CPUSet: Of(0), Valid: true}
+			switch err := openEchoDB(t).QueryRow("echo", params.arg).Scan(&got); {
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case got.Valid != params.arg.Valid || !got.CPUSet.Equal(params.arg.CPUSet):
+				t.Errorf("unexpected cpuset: got %+v, want %+v", got, params.arg)
+			}
+		})
+	}
+}
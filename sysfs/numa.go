@@ -0,0 +1,169 @@
+package sysfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.vallahaye.net/cpuset"
+)
+
+// nodeDir is the directory of the NUMA node files, relative to the root of
+// sysfs.
+const nodeDir = "devices/system/node"
+
+// localDistance is the distance of a NUMA node to itself.
+const localDistance = 10
+
+// A NUMA describes the online NUMA nodes of the system: their CPUs and the
+// distances between them, as reported by the ACPI SLIT table.
+type NUMA struct {
+	nodes     []int
+	cpus      []cpuset.CPUSet
+	distances [][]int
+}
+
+// ReadNUMA reads the online NUMA nodes of the system from the nodeN
+// directories of sysfs. On kernels built without NUMA support, the online
+// CPUs of the system are reported as a single node 0.
+func (fsys FS) ReadNUMA() (*NUMA, error) {
+	online, err := fsys.readList(nodeDir, "online")
+	if errors.Is(err, fs.ErrNotExist) {
+		cpus, err := fsys.Online()
+		if err != nil {
+			return nil, err
+		}
+
+		return &NUMA{nodes: []int{0}, cpus: []cpuset.CPUSet{cpus}, distances: [][]int{{localDistance}}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	n := &NUMA{}
+	for id := range online.All() {
+		n.nodes = append(n.nodes, int(id))
+	}
+
+	for _, id := range n.nodes {
+		dir := "node" + strconv.Itoa(id)
+
+		cpus, err := fsys.readList(nodeDir, dir, "cpulist")
+		if err != nil {
+			return nil, err
+		}
+
+		distances, err := fsys.readInts(nodeDir, dir, "distance")
+		if err != nil {
+			return nil, err
+		}
+
+		// The kernel writes the distance to each online node, in
+		// ascending order.
+		if len(distances) != len(n.nodes) {
+			return nil, fmt.Errorf("sysfs: %s: got %d distances, want %d", fsys.path(nodeDir, dir, "distance"), len(distances), len(n.nodes))
+		}
+
+		n.cpus = append(n.cpus, cpus)
+		n.distances = append(n.distances, distances)
+	}
+
+	return n, nil
+}
+
+// ReadNUMA is like [FS.ReadNUMA] but reads from [DefaultRoot].
+func ReadNUMA() (*NUMA, error) {
+	return FS{}.ReadNUMA()
+}
+
+// readInts decodes the space-separated integers of the named file of sysfs.
+func (fsys FS) readInts(elem ...string) ([]int, error) {
+	data, err := os.ReadFile(fsys.path(elem...))
+	if err != nil {
+		return nil, err
+	}
+
+	var ints []int
+	for _, field := range strings.Fields(string(data)) {
+		i, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+
+		ints = append(ints, i)
+	}
+
+	return ints, nil
+}
+
+// Nodes returns the numbers of the online nodes, in ascending order.
+func (n *NUMA) Nodes() []int {
+	return slices.Clone(n.nodes)
+}
+
+// CPUs returns the CPUs of the node id. It returns an empty set if the node
+// is not online or has no CPUs, such as a memory-only node.
+func (n *NUMA) CPUs(id int) cpuset.CPUSet {
+	i, ok := slices.BinarySearch(n.nodes, id)
+	if !ok {
+		return cpuset.CPUSet{}
+	}
+
+	return n.cpus[i].Clone()
+}
+
+// NodeOf returns the node of the CPU cpu. It reports whether the CPU
+// belongs to an online node.
+func (n *NUMA) NodeOf(cpu uint) (int, bool) {
+	for i, cpus := range n.cpus {
+		if cpus.Contains(cpu) {
+			return n.nodes[i], true
+		}
+	}
+
+	return 0, false
+}
+
+// NodesOf returns the numbers of the nodes s overlaps, in ascending order.
+func (n *NUMA) NodesOf(s cpuset.CPUSet) []int {
+	var ids []int
+	for i, cpus := range n.cpus {
+		if cpus.Overlaps(s) {
+			ids = append(ids, n.nodes[i])
+		}
+	}
+
+	return ids
+}
+
+// Distance returns the distance from the node from to the node to, relative
+// to the distance of a node to itself, which is 10. It reports whether both
+// nodes are online.
+func (n *NUMA) Distance(from, to int) (int, bool) {
+	i, ok := slices.BinarySearch(n.nodes, from)
+	if !ok {
+		return 0, false
+	}
+
+	j, ok := slices.BinarySearch(n.nodes, to)
+	if !ok {
+		return 0, false
+	}
+
+	return n.distances[i][j], true
+}
+
+// Distances returns the distance matrix of the nodes: the distance from the
+// i-th node of [NUMA.Nodes] to the j-th one is at index [i][j].
+func (n *NUMA) Distances() [][]int {
+	distances := make([][]int, len(n.distances))
+	for i, row := range n.distances {
+		distances[i] = slices.Clone(row)
+	}
+
+	return distances
+}
@@ -0,0 +1,140 @@
+package sysfs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFSReadNUMA(t *testing.T) {
+	for _, params := range []struct {
+		name      string
+		root      string
+		nodes     []int
+		cpus      []string
+		distances [][]int
+		nodeOf    map[uint]int
+		nodesOf   string
+		nodesWant []int
+	}{
+		{
+			name:      "laptop with a single node",
+			root:      "testdata/laptop",
+			nodes:     []int{0},
+			cpus:      []string{"0-7"},
+			distances: [][]int{{10}},
+			nodeOf:    map[uint]int{0: 0, 7: 0},
+			nodesOf:   "1-2",
+			nodesWant: []int{0},
+		},
+		{
+			name:      "two-socket server",
+			root:      "testdata/server",
+			nodes:     []int{0, 1},
+			cpus:      []string{"0-3,8-11", "4-7,12-13"},
+			distances: [][]int{{10, 21}, {21, 10}},
+			nodeOf:    map[uint]int{0: 0, 4: 1, 11: 0, 13: 1},
+			nodesOf:   "3-4",
+			nodesWant: []int{0, 1},
+		},
+		{
+			name:      "arm with a memory-only node",
+			root:      "testdata/arm",
+			nodes:     []int{0, 1, 2},
+			cpus:      []string{"0-3", "4-7", ""},
+			distances: [][]int{{10, 12, 20}, {12, 10, 20}, {20, 20, 10}},
+			nodeOf:    map[uint]int{3: 0, 4: 1},
+			nodesOf:   "5-7",
+			nodesWant: []int{1},
+		},
+		{
+			name:      "legacy kernel without NUMA support",
+			root:      "testdata/legacy",
+			nodes:     []int{0},
+			cpus:      []string{"0-3"},
+			distances: [][]int{{10}},
+			nodeOf:    map[uint]int{3: 0},
+			nodesOf:   "0-3",
+			nodesWant: []int{0},
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			numa, err := FS{Root: params.root}.ReadNUMA()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := numa.Nodes(); !slices.Equal(got, params.nodes) {
+				t.Errorf("unexpected nodes: got %v, want %v", got, params.nodes)
+			}
+
+			for i, id := range params.nodes {
+				if got := numa.CPUs(id); got.ListString() != params.cpus[i] {
+					t.Errorf("unexpected CPUs of node %d: got %v, want %v", id, got, params.cpus[i])
+				}
+
+				for j, to := range params.nodes {
+					if got, ok := numa.Distance(id, to); !ok || got != params.distances[i][j] {
+						t.Errorf("unexpected distance from node %d to %d: got %d, want %d", id, to, got, params.distances[i][j])
+					}
+				}
+			}
+
+			if got := numa.Distances(); !slices.EqualFunc(got, params.distances, slices.Equal) {
+				t.Errorf("unexpected distances: got %v, want %v", got, params.distances)
+			}
+
+			for cpu, want := range params.nodeOf {
+				if got, ok := numa.NodeOf(cpu); !ok || got != want {
+					t.Errorf("unexpected node of CPU %d: got %d, want %d", cpu, got, want)
+				}
+			}
+
+			if got := numa.NodesOf(mustParseList(t, params.nodesOf)); !slices.Equal(got, params.nodesWant) {
+				t.Errorf("unexpected nodes of %s: got %v, want %v", params.nodesOf, got, params.nodesWant)
+			}
+		})
+	}
+}
+
+func TestNUMAUnknownNode(t *testing.T) {
+	numa, err := FS{Root: "testdata/server"}.ReadNUMA()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := numa.CPUs(2); got.Len() != 0 {
+		t.Errorf("unexpected CPUs of node 2: got %v, want none", got)
+	}
+
+	if _, ok := numa.NodeOf(14); ok {
+		t.Error("unexpected node of CPU 14")
+	}
+
+	if _, ok := numa.Distance(0, 2); ok {
+		t.Error("unexpected distance from node 0 to 2")
+	}
+}
+
+func TestFSReadNUMAInvalidDistance(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, nodeDir, "node0")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string]string{
+		filepath.Join(root, nodeDir, "online"): "0\n",
+		filepath.Join(dir, "cpulist"):          "0-3\n",
+		filepath.Join(dir, "distance"):         "10 20\n",
+	} {
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := (FS{Root: root}).ReadNUMA(); err == nil {
+		t.Error("expected error")
+	}
+}
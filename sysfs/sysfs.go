@@ -0,0 +1,122 @@
+// Package sysfs reads the CPUs of the system, their topology and their NUMA
+// nodes as [cpuset.CPUSet] values from the sysfs file system of Linux, as
+// documented in the [kernel ABI documentation].
+//
+// [kernel ABI documentation]: https://docs.kernel.org/admin-guide/abi-stable-files.html#abi-file-stable-sysfs-devices-system-cpu
+package sysfs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.vallahaye.net/cpuset"
+)
+
+// DefaultRoot is the directory sysfs is usually mounted on.
+const DefaultRoot = "/sys"
+
+// cpuDir is the directory of the CPU files, relative to the root of sysfs.
+const cpuDir = "devices/system/cpu"
+
+// An FS reads the CPUs of the system from the sysfs file system mounted on
+// Root. The zero value of an FS reads from [DefaultRoot].
+//
+// Root may point to a copy of sysfs, such as a fixture tree in tests.
+type FS struct {
+	Root string
+}
+
+// Possible returns the CPUs that have been allocated resources and can be
+// brought online if they are present.
+func (fsys FS) Possible() (cpuset.CPUSet, error) {
+	return fsys.readList(cpuDir, "possible")
+}
+
+// Present returns the CPUs that have been identified as being present in the
+// system.
+func (fsys FS) Present() (cpuset.CPUSet, error) {
+	return fsys.readList(cpuDir, "present")
+}
+
+// Online returns the CPUs that are online and being scheduled.
+func (fsys FS) Online() (cpuset.CPUSet, error) {
+	return fsys.readList(cpuDir, "online")
+}
+
+// Offline returns the CPUs that are not online, either because they have
+// been taken offline or because they exceed the limit of CPUs allowed by the
+// kernel configuration.
+func (fsys FS) Offline() (cpuset.CPUSet, error) {
+	return fsys.readList(cpuDir, "offline")
+}
+
+// Isolated returns the CPUs isolated from the scheduler with the isolcpus
+// kernel parameter.
+func (fsys FS) Isolated() (cpuset.CPUSet, error) {
+	return fsys.readList(cpuDir, "isolated")
+}
+
+// NohzFull returns the CPUs in adaptive-tick mode, set with the nohz_full
+// kernel parameter. It returns an empty set if the kernel does not support
+// the mode.
+func (fsys FS) NohzFull() (cpuset.CPUSet, error) {
+	return fsys.readList(cpuDir, "nohz_full")
+}
+
+// path returns the path of the named file of sysfs.
+func (fsys FS) path(elem ...string) string {
+	root := fsys.Root
+	if root == "" {
+		root = DefaultRoot
+	}
+
+	return filepath.Join(append([]string{root}, elem...)...)
+}
+
+// readList decodes the list string of the named file of sysfs.
+func (fsys FS) readList(elem ...string) (cpuset.CPUSet, error) {
+	data, err := os.ReadFile(fsys.path(elem...))
+	if err != nil {
+		return cpuset.CPUSet{}, err
+	}
+
+	// Kernels built without support for a feature may print "(null)" for
+	// its CPUs, as they do for nohz_full.
+	s := string(data)
+	if strings.TrimSpace(s) == "(null)" {
+		return cpuset.CPUSet{}, nil
+	}
+
+	return cpuset.ParseOptions{TrimSpace: true}.ParseList(s)
+}
+
+// Possible is like [FS.Possible] but reads from [DefaultRoot].
+func Possible() (cpuset.CPUSet, error) {
+	return FS{}.Possible()
+}
+
+// Present is like [FS.Present] but reads from [DefaultRoot].
+func Present() (cpuset.CPUSet, error) {
+	return FS{}.Present()
+}
+
+// Online is like [FS.Online] but reads from [DefaultRoot].
+func Online() (cpuset.CPUSet, error) {
+	return FS{}.Online()
+}
+
+// Offline is like [FS.Offline] but reads from [DefaultRoot].
+func Offline() (cpuset.CPUSet, error) {
+	return FS{}.Offline()
+}
+
+// Isolated is like [FS.Isolated] but reads from [DefaultRoot].
+func Isolated() (cpuset.CPUSet, error) {
+	return FS{}.Isolated()
+}
+
+// NohzFull is like [FS.NohzFull] but reads from [DefaultRoot].
+func NohzFull() (cpuset.CPUSet, error) {
+	return FS{}.NohzFull()
+}
@@ -0,0 +1,104 @@
+package sysfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.vallahaye.net/cpuset"
+)
+
+func TestFS(t *testing.T) {
+	for _, params := range []struct {
+		name     string
+		root     string
+		possible string
+		present  string
+		online   string
+		offline  string
+		isolated string
+		nohzFull string
+	}{
+		{
+			name:     "laptop",
+			root:     "testdata/laptop",
+			possible: "0-7",
+			present:  "0-7",
+			online:   "0-7",
+			offline:  "",
+			isolated: "",
+			nohzFull: "",
+		},
+		{
+			name:     "server",
+			root:     "testdata/server",
+			possible: "0-31",
+			present:  "0-15",
+			online:   "0-13",
+			offline:  "14-31",
+			isolated: "2-3,10-11",
+			nohzFull: "2-3,10-11",
+		},
+	} {
+		fsys := FS{Root: params.root}
+		for _, file := range []struct {
+			name string
+			fn   func() (cpuset.CPUSet, error)
+			want string
+		}{
+			{name: "possible", fn: fsys.Possible, want: params.possible},
+			{name: "present", fn: fsys.Present, want: params.present},
+			{name: "online", fn: fsys.Online, want: params.online},
+			{name: "offline", fn: fsys.Offline, want: params.offline},
+			{name: "isolated", fn: fsys.Isolated, want: params.isolated},
+			{name: "nohz_full", fn: fsys.NohzFull, want: params.nohzFull},
+		} {
+			t.Run(params.name+"/"+file.name, func(t *testing.T) {
+				switch got, err := file.fn(); {
+				case err != nil:
+					t.Errorf("unexpected error: %v", err)
+				case got.ListString() != file.want:
+					t.Errorf("unexpected cpuset: got %q, want %q", got.ListString(), file.want)
+				}
+			})
+		}
+	}
+}
+
+func TestFSErrors(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, cpuDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "online"), []byte("0-x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := FS{Root: root}
+
+	var perr *cpuset.ParseError
+	if _, err := fsys.Online(); !errors.As(err, &perr) {
+		t.Errorf("unexpected error: got %v, want a ParseError", err)
+	}
+
+	if _, err := fsys.Possible(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("unexpected error: got %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestOnline(t *testing.T) {
+	if _, err := os.Stat(filepath.Join(DefaultRoot, cpuDir, "online")); err != nil {
+		t.Skip("sysfs not available:", err)
+	}
+
+	switch got, err := Online(); {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case got.Len() == 0:
+		t.Error("unexpected empty cpuset")
+	}
+}
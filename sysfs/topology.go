@@ -0,0 +1,224 @@
+package sysfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.vallahaye.net/cpuset"
+)
+
+// A CPU describes the place of a logical CPU in the topology of the system,
+// as documented in the [kernel CPU topology documentation].
+//
+// [kernel CPU topology documentation]: https://docs.kernel.org/admin-guide/cputopology.html
+type CPU struct {
+	ID        uint // the number of the CPU
+	PackageID int  // the physical socket number of the CPU
+	DieID     int  // the die number of the CPU, or -1 if unknown
+	ClusterID int  // the cluster number of the CPU, or -1 if unknown
+	CoreID    int  // the core number of the CPU, unique within its package
+
+	Core    cpuset.CPUSet // the SMT siblings of the CPU, itself included
+	Cluster cpuset.CPUSet // the CPUs of the cluster of the CPU
+	Die     cpuset.CPUSet // the CPUs of the die of the CPU
+	Package cpuset.CPUSet // the CPUs of the package of the CPU
+}
+
+// A Topology describes how the online CPUs of the system are grouped into
+// cores, clusters, dies and packages.
+type Topology struct {
+	cpus  cpuset.CPUSet
+	byID  map[uint]CPU
+	cores []cpuset.CPUSet
+}
+
+// ReadTopology reads the topology of the online CPUs of the system from the
+// cpuN/topology directories of sysfs. The files introduced by Linux 5.x are
+// preferred, falling back to thread_siblings_list and core_siblings_list on
+// older kernels. When the die or cluster of a CPU is unknown, they are
+// assumed to match its package or core.
+func (fsys FS) ReadTopology() (*Topology, error) {
+	online, err := fsys.Online()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Topology{cpus: online, byID: make(map[uint]CPU, online.Len())}
+	for id := range online.All() {
+		cpu, err := fsys.readCPU(id)
+		if err != nil {
+			return nil, err
+		}
+
+		t.byID[id] = cpu
+		if minID, _ := cpu.Core.Min(); minID == id {
+			t.cores = append(t.cores, cpu.Core)
+		}
+	}
+
+	return t, nil
+}
+
+// ReadTopology is like [FS.ReadTopology] but reads from [DefaultRoot].
+func ReadTopology() (*Topology, error) {
+	return FS{}.ReadTopology()
+}
+
+// readCPU reads the topology of the CPU id.
+func (fsys FS) readCPU(id uint) (CPU, error) {
+	dir := cpuDir + "/cpu" + strconv.FormatUint(uint64(id), 10) + "/topology"
+
+	cpu := CPU{ID: id, DieID: -1, ClusterID: -1}
+	var err error
+
+	if cpu.PackageID, err = fsys.readInt(dir, "physical_package_id"); err != nil {
+		return CPU{}, err
+	}
+
+	if cpu.CoreID, err = fsys.readInt(dir, "core_id"); err != nil {
+		return CPU{}, err
+	}
+
+	if cpu.Core, err = fsys.readListFallback(dir, "core_cpus_list", "thread_siblings_list"); err != nil {
+		return CPU{}, err
+	}
+
+	if cpu.Package, err = fsys.readListFallback(dir, "package_cpus_list", "core_siblings_list"); err != nil {
+		return CPU{}, err
+	}
+
+	switch die, err := fsys.readList(dir, "die_cpus_list"); {
+	case errors.Is(err, fs.ErrNotExist):
+		cpu.Die = cpu.Package
+	case err != nil:
+		return CPU{}, err
+	default:
+		cpu.Die = die
+		if cpu.DieID, err = fsys.readInt(dir, "die_id"); err != nil {
+			return CPU{}, err
+		}
+	}
+
+	switch cluster, err := fsys.readList(dir, "cluster_cpus_list"); {
+	case errors.Is(err, fs.ErrNotExist):
+		cpu.Cluster = cpu.Core
+	case err != nil:
+		return CPU{}, err
+	default:
+		cpu.Cluster = cluster
+		if cpu.ClusterID, err = fsys.readInt(dir, "cluster_id"); err != nil {
+			return CPU{}, err
+		}
+	}
+
+	return cpu, nil
+}
+
+// readListFallback decodes the list string of the named file of the
+// directory dir, or of the fallback file if it does not exist.
+func (fsys FS) readListFallback(dir, name, fallback string) (cpuset.CPUSet, error) {
+	s, err := fsys.readList(dir, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fsys.readList(dir, fallback)
+	}
+
+	return s, err
+}
+
+// readInt decodes the integer of the named file of sysfs.
+func (fsys FS) readInt(elem ...string) (int, error) {
+	data, err := os.ReadFile(fsys.path(elem...))
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// CPUs returns the CPUs described by t.
+func (t *Topology) CPUs() cpuset.CPUSet {
+	return t.cpus.Clone()
+}
+
+// CPU returns the description of the CPU id. It reports whether the CPU is
+// described by t.
+func (t *Topology) CPU(id uint) (CPU, bool) {
+	cpu, ok := t.byID[id]
+	cpu.Core, cpu.Cluster = cpu.Core.Clone(), cpu.Cluster.Clone()
+	cpu.Die, cpu.Package = cpu.Die.Clone(), cpu.Package.Clone()
+	return cpu, ok
+}
+
+// Cores returns the CPUs of each core, ordered by their lowest CPU.
+func (t *Topology) Cores() []cpuset.CPUSet {
+	cores := make([]cpuset.CPUSet, len(t.cores))
+	for i, core := range t.cores {
+		cores[i] = core.Clone()
+	}
+
+	return cores
+}
+
+// Packages returns the physical package numbers of the CPUs, in ascending
+// order.
+func (t *Topology) Packages() []int {
+	var ids []int
+	for _, cpu := range t.byID {
+		if !slices.Contains(ids, cpu.PackageID) {
+			ids = append(ids, cpu.PackageID)
+		}
+	}
+
+	slices.Sort(ids)
+	return ids
+}
+
+// SiblingsOf returns the SMT siblings of the CPU id, itself included. It
+// returns an empty set if the CPU is not described by t.
+func (t *Topology) SiblingsOf(id uint) cpuset.CPUSet {
+	cpu := t.byID[id]
+	return cpu.Core.Clone()
+}
+
+// CoresOf returns the CPUs of the cores s overlaps, that is s extended with
+// the SMT siblings of its CPUs.
+func (t *Topology) CoresOf(s cpuset.CPUSet) cpuset.CPUSet {
+	var cores cpuset.CPUSet
+	for _, core := range t.cores {
+		if core.Overlaps(s) {
+			cores.UnionWith(core)
+		}
+	}
+
+	return cores
+}
+
+// FullCoresIn returns the CPUs of the cores s fully contains, that is s
+// without the CPUs whose SMT siblings are not all in s.
+func (t *Topology) FullCoresIn(s cpuset.CPUSet) cpuset.CPUSet {
+	var cores cpuset.CPUSet
+	for _, core := range t.cores {
+		if core.IsSubset(s) {
+			cores.UnionWith(core)
+		}
+	}
+
+	return cores
+}
+
+// Package returns the CPUs of the physical package id. It returns an empty
+// set if no CPU of t belongs to the package.
+func (t *Topology) Package(id int) cpuset.CPUSet {
+	var pkg cpuset.CPUSet
+	for cpuID, cpu := range t.byID {
+		if cpu.PackageID == id {
+			pkg.Add(cpuID)
+		}
+	}
+
+	return pkg
+}
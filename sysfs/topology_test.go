@@ -0,0 +1,205 @@
+package sysfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.vallahaye.net/cpuset"
+)
+
+func mustParseList(t *testing.T, s string) cpuset.CPUSet {
+	t.Helper()
+
+	cset, err := cpuset.ParseList(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cset
+}
+
+func TestFSReadTopology(t *testing.T) {
+	for _, params := range []struct {
+		name        string
+		root        string
+		cpus        string
+		cores       []string
+		packages    []int
+		cpu         uint
+		cpuIDs      [4]int    // package, die, cluster and core numbers of cpu
+		cpuLists    [4]string // core, cluster, die and package of cpu
+		siblingsOf  uint
+		siblings    string
+		coresOf     string
+		coresOfWant string
+		fullCoresIn string
+		fullCores   string
+		packageID   int
+		packageCPUs string
+	}{
+		{
+			name:        "laptop with SMT",
+			root:        "testdata/laptop",
+			cpus:        "0-7",
+			cores:       []string{"0,4", "1,5", "2,6", "3,7"},
+			packages:    []int{0},
+			cpu:         5,
+			cpuIDs:      [4]int{0, 0, 1, 1},
+			cpuLists:    [4]string{"1,5", "1,5", "0-7", "0-7"},
+			siblingsOf:  2,
+			siblings:    "2,6",
+			coresOf:     "0-1",
+			coresOfWant: "0-1,4-5",
+			fullCoresIn: "0-2,4-5",
+			fullCores:   "0-1,4-5",
+			packageID:   0,
+			packageCPUs: "0-7",
+		},
+		{
+			name:        "two-socket server with offline CPUs",
+			root:        "testdata/server",
+			cpus:        "0-13",
+			cores:       []string{"0,8", "1,9", "2,10", "3,11", "4,12", "5,13", "6", "7"},
+			packages:    []int{0, 1},
+			cpu:         12,
+			cpuIDs:      [4]int{1, 0, 0, 0},
+			cpuLists:    [4]string{"4,12", "4,12", "4-7,12-13", "4-7,12-13"},
+			siblingsOf:  7,
+			siblings:    "7",
+			coresOf:     "3-4",
+			coresOfWant: "3-4,11-12",
+			fullCoresIn: "4-7,12",
+			fullCores:   "4,6-7,12",
+			packageID:   1,
+			packageCPUs: "4-7,12-13",
+		},
+		{
+			name:        "arm with clusters",
+			root:        "testdata/arm",
+			cpus:        "0-7",
+			cores:       []string{"0", "1", "2", "3", "4", "5", "6", "7"},
+			packages:    []int{0},
+			cpu:         6,
+			cpuIDs:      [4]int{0, 0, 1, 6},
+			cpuLists:    [4]string{"6", "4-7", "0-7", "0-7"},
+			siblingsOf:  3,
+			siblings:    "3",
+			coresOf:     "3",
+			coresOfWant: "3",
+			fullCoresIn: "0-2",
+			fullCores:   "0-2",
+			packageID:   0,
+			packageCPUs: "0-7",
+		},
+		{
+			name:        "legacy kernel",
+			root:        "testdata/legacy",
+			cpus:        "0-3",
+			cores:       []string{"0", "1", "2", "3"},
+			packages:    []int{0, 1},
+			cpu:         3,
+			cpuIDs:      [4]int{1, -1, -1, 1},
+			cpuLists:    [4]string{"3", "3", "2-3", "2-3"},
+			siblingsOf:  0,
+			siblings:    "0",
+			coresOf:     "1-2",
+			coresOfWant: "1-2",
+			fullCoresIn: "1-2",
+			fullCores:   "1-2",
+			packageID:   1,
+			packageCPUs: "2-3",
+		},
+	} {
+		t.Run(params.name, func(t *testing.T) {
+			topo, err := FS{Root: params.root}.ReadTopology()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := topo.CPUs(); !got.Equal(mustParseList(t, params.cpus)) {
+				t.Errorf("unexpected CPUs: got %v, want %v", got, params.cpus)
+			}
+
+			var cores []string
+			for _, core := range topo.Cores() {
+				cores = append(cores, core.ListString())
+			}
+			if !slices.Equal(cores, params.cores) {
+				t.Errorf("unexpected cores: got %q, want %q", cores, params.cores)
+			}
+
+			if got := topo.Packages(); !slices.Equal(got, params.packages) {
+				t.Errorf("unexpected packages: got %v, want %v", got, params.packages)
+			}
+
+			cpu, ok := topo.CPU(params.cpu)
+			ids := [4]int{cpu.PackageID, cpu.DieID, cpu.ClusterID, cpu.CoreID}
+			lists := [4]string{cpu.Core.ListString(), cpu.Cluster.ListString(), cpu.Die.ListString(), cpu.Package.ListString()}
+			switch {
+			case !ok:
+				t.Errorf("CPU %d not found", params.cpu)
+			case cpu.ID != params.cpu:
+				t.Errorf("unexpected CPU number: got %d, want %d", cpu.ID, params.cpu)
+			case ids != params.cpuIDs:
+				t.Errorf("unexpected CPU numbers: got %v, want %v", ids, params.cpuIDs)
+			case lists != params.cpuLists:
+				t.Errorf("unexpected CPU lists: got %q, want %q", lists, params.cpuLists)
+			}
+
+			if got := topo.SiblingsOf(params.siblingsOf); got.ListString() != params.siblings {
+				t.Errorf("unexpected siblings of %d: got %v, want %v", params.siblingsOf, got, params.siblings)
+			}
+
+			if got := topo.CoresOf(mustParseList(t, params.coresOf)); got.ListString() != params.coresOfWant {
+				t.Errorf("unexpected cores of %s: got %v, want %v", params.coresOf, got, params.coresOfWant)
+			}
+
+			if got := topo.FullCoresIn(mustParseList(t, params.fullCoresIn)); got.ListString() != params.fullCores {
+				t.Errorf("unexpected full cores in %s: got %v, want %v", params.fullCoresIn, got, params.fullCores)
+			}
+
+			if got := topo.Package(params.packageID); got.ListString() != params.packageCPUs {
+				t.Errorf("unexpected package %d: got %v, want %v", params.packageID, got, params.packageCPUs)
+			}
+		})
+	}
+}
+
+func TestTopologyUnknownCPU(t *testing.T) {
+	topo, err := FS{Root: "testdata/laptop"}.ReadTopology()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := topo.CPU(8); ok {
+		t.Error("unexpected CPU 8")
+	}
+
+	if got := topo.SiblingsOf(8); got.Len() != 0 {
+		t.Errorf("unexpected siblings of 8: got %v, want none", got)
+	}
+
+	if got := topo.Package(1); got.Len() != 0 {
+		t.Errorf("unexpected package 1: got %v, want none", got)
+	}
+}
+
+func TestFSReadTopologyMissingCPU(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, cpuDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "online"), []byte("0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := (FS{Root: root}).ReadTopology(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("unexpected error: got %v, want %v", err, fs.ErrNotExist)
+	}
+}